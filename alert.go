@@ -0,0 +1,132 @@
+package logger
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// AlertRule fires when Threshold records at Level or above are logged within
+// Window. A nil Level means ERROR.
+type AlertRule struct {
+	Name      string        `json:"name,omitempty"`
+	Level     slog.Leveler  `json:"-"`
+	Window    time.Duration `json:"window"`
+	Threshold int           `json:"threshold"`
+}
+
+type Alert struct {
+	Rule  AlertRule
+	Count int
+	Since time.Time
+	At    time.Time
+}
+
+// AlertHandler tracks record rates over sliding windows and calls fn when a
+// rule is breached, at most once per window. With a nil fn a WARN record
+// "error threshold exceeded" is written instead.
+type AlertHandler struct {
+	slog.Handler
+	core *alertCore
+}
+
+type alertCore struct {
+	base  slog.Handler
+	fn    func(Alert)
+	rules []*alertWindow
+}
+
+type alertWindow struct {
+	rule AlertRule
+
+	mu    sync.Mutex
+	times []time.Time
+	next  int
+	full  bool
+	fired time.Time
+}
+
+//	logger.NewAlertHandler(h, []logger.AlertRule{{Window: time.Minute, Threshold: 50}}, func(a logger.Alert) {
+//		pager.Notify(a.Rule.Name, a.Count)
+//	})
+func NewAlertHandler(h slog.Handler, rules []AlertRule, fn func(Alert)) *AlertHandler {
+	c := &alertCore{base: h, fn: fn}
+	for _, r := range rules {
+		if r.Threshold < 1 || r.Window <= 0 {
+			continue
+		}
+		if r.Level == nil {
+			r.Level = slog.LevelError
+		}
+		c.rules = append(c.rules, &alertWindow{rule: r, times: make([]time.Time, r.Threshold)})
+	}
+	return &AlertHandler{Handler: h, core: c}
+}
+
+func (h *AlertHandler) Handle(ctx context.Context, r slog.Record) error {
+	err := h.Handler.Handle(ctx, r)
+
+	now := r.Time
+	if now.IsZero() {
+		now = time.Now()
+	}
+	for _, w := range h.core.rules {
+		if r.Level < w.rule.Level.Level() {
+			continue
+		}
+		if a, ok := w.observe(now); ok {
+			h.core.alert(ctx, a)
+		}
+	}
+	return err
+}
+
+func (h *AlertHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &AlertHandler{Handler: h.Handler.WithAttrs(attrs), core: h.core}
+}
+
+func (h *AlertHandler) WithGroup(name string) slog.Handler {
+	return &AlertHandler{Handler: h.Handler.WithGroup(name), core: h.core}
+}
+
+// observe records a hit at now and reports whether the rule is breached.
+// The window keeps the last Threshold hit times in a ring: the rule is
+// breached when the oldest of them is still inside Window.
+func (w *alertWindow) observe(now time.Time) (Alert, bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.times[w.next] = now
+	w.next = (w.next + 1) % len(w.times)
+	if w.next == 0 {
+		w.full = true
+	}
+	if !w.full {
+		return Alert{}, false
+	}
+
+	oldest := w.times[w.next]
+	if now.Sub(oldest) > w.rule.Window || now.Sub(w.fired) < w.rule.Window {
+		return Alert{}, false
+	}
+	w.fired = now
+	return Alert{Rule: w.rule, Count: w.rule.Threshold, Since: oldest, At: now}, true
+}
+
+func (c *alertCore) alert(ctx context.Context, a Alert) {
+	if c.fn != nil {
+		c.fn(a)
+		return
+	}
+
+	r := slog.NewRecord(a.At, slog.LevelWarn, "error threshold exceeded", 0)
+	r.AddAttrs(
+		slog.String("rule", a.Rule.Name),
+		slog.String("rule_level", a.Rule.Level.Level().String()),
+		slog.Int("count", a.Count),
+		slog.Duration("window", a.Rule.Window),
+		slog.Time("since", a.Since),
+	)
+	_ = c.base.Handle(ctx, r)
+}
@@ -0,0 +1,126 @@
+package logger
+
+import (
+	"context"
+	"log/slog"
+	"sort"
+)
+
+// WithAttrOrder sorts the attrs of every record by key, the keys of
+// priority first in that order and the others alphabetically, so important
+// attrs stand out in consoles and output diffs stay stable. Attrs from
+// With are sorted with the record's; members of groups are sorted
+// alphabetically.
+//
+//	logger.NewLogger(os.Stdout, logger.WithAttrOrder(logger.RequestIDKey, logger.TraceIDKey))
+func WithAttrOrder(priority ...string) Option {
+	return func(opts *loggerOptions) {
+		opts.attrOrder = &attrOrder{priority: priority}
+	}
+}
+
+type attrOrder struct {
+	priority []string
+}
+
+func (o *attrOrder) rank(key string) int {
+	for i, k := range o.priority {
+		if k == key {
+			return i
+		}
+	}
+	return len(o.priority)
+}
+
+func (o *attrOrder) sort(attrs []slog.Attr, top bool) {
+	for i, a := range attrs {
+		if a.Value.Kind() == slog.KindGroup {
+			group := append([]slog.Attr(nil), a.Value.Group()...)
+			o.sort(group, false)
+			attrs[i].Value = slog.GroupValue(group...)
+		}
+	}
+	sort.SliceStable(attrs, func(i, j int) bool {
+		if top {
+			if ri, rj := o.rank(attrs[i].Key), o.rank(attrs[j].Key); ri != rj {
+				return ri < rj
+			}
+		}
+		return attrs[i].Key < attrs[j].Key
+	})
+}
+
+// AttrOrderHandler keeps the attrs and groups of WithAttrs and WithGroup
+// instead of passing them to the inner handler, and hands it every record
+// with all its attrs sorted. The source of the record stays top-level and
+// last, as it may be renamed by the encoder.
+type AttrOrderHandler struct {
+	slog.Handler
+	order  *attrOrder
+	attrs  []slog.Attr
+	groups []string
+}
+
+// logger.NewAttrOrderHandler(h, logger.RequestIDKey)
+func NewAttrOrderHandler(h slog.Handler, priority ...string) *AttrOrderHandler {
+	return &AttrOrderHandler{Handler: h, order: &attrOrder{priority: priority}}
+}
+
+func (h *AttrOrderHandler) Handle(ctx context.Context, r slog.Record) error {
+	var source []slog.Attr
+	own := make([]slog.Attr, 0, r.NumAttrs())
+	r.Attrs(func(a slog.Attr) bool {
+		if _, ok := a.Value.Any().(*slog.Source); ok && a.Key == slog.SourceKey {
+			source = append(source, a)
+		} else {
+			own = append(own, a)
+		}
+		return true
+	})
+
+	attrs := nest(h.attrs, h.groups, own)
+	h.order.sort(attrs, true)
+	attrs = append(attrs, source...)
+
+	nr := slog.NewRecord(r.Time, r.Level, r.Message, r.PC)
+	nr.AddAttrs(attrs...)
+	return h.Handler.Handle(ctx, nr)
+}
+
+func (h *AttrOrderHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+	h2 := *h
+	h2.attrs = nest(h.attrs, h.groups, attrs)
+	return &h2
+}
+
+func (h *AttrOrderHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	h2 := *h
+	h2.groups = append(h.groups[:len(h.groups):len(h.groups)], name)
+	return &h2
+}
+
+// nest returns attrs with add appended inside the groups path, merging
+// into the last group of that name already in attrs. attrs is not
+// modified.
+func nest(attrs []slog.Attr, groups []string, add []slog.Attr) []slog.Attr {
+	out := append([]slog.Attr(nil), attrs...)
+	if len(groups) == 0 {
+		return append(out, add...)
+	}
+	if len(add) == 0 {
+		return out
+	}
+	for i := len(out) - 1; i >= 0; i-- {
+		if out[i].Key == groups[0] && out[i].Value.Kind() == slog.KindGroup {
+			out[i] = slog.Attr{Key: groups[0], Value: slog.GroupValue(nest(out[i].Value.Group(), groups[1:], add)...)}
+			return out
+		}
+	}
+	return append(out, slog.Attr{Key: groups[0], Value: slog.GroupValue(nest(nil, groups[1:], add)...)})
+}
@@ -0,0 +1,39 @@
+package logger
+
+import (
+	"log/slog"
+	"sync"
+)
+
+// maxPooledAttrs keeps slices grown by an unusually large record from being
+// held by the pool.
+const maxPooledAttrs = 64
+
+var attrPool = sync.Pool{
+	New: func() any {
+		attrs := make([]slog.Attr, 0, 16)
+		return &attrs
+	},
+}
+
+// GetAttrs returns an empty scratch slice from a pool. Records copy their
+// attrs, so the slice can be handed back with ReleaseAttrs as soon as the
+// LogAttrs call using it returns.
+//
+//	attrs := logger.GetAttrs()
+//	defer logger.ReleaseAttrs(attrs)
+//	*attrs = append(*attrs, slog.Int("status", status))
+//	slog.Default().LogAttrs(ctx, slog.LevelInfo, "", *attrs...)
+func GetAttrs() *[]slog.Attr {
+	return attrPool.Get().(*[]slog.Attr)
+}
+
+// ReleaseAttrs returns a slice obtained from GetAttrs to the pool.
+func ReleaseAttrs(attrs *[]slog.Attr) {
+	if attrs == nil || cap(*attrs) > maxPooledAttrs {
+		return
+	}
+	clear(*attrs)
+	*attrs = (*attrs)[:0]
+	attrPool.Put(attrs)
+}
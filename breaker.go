@@ -0,0 +1,179 @@
+package logger
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync/atomic"
+	"time"
+)
+
+// ErrHandlerOpen is returned by BreakerHandler for a record dropped while
+// its circuit breaker is open.
+var ErrHandlerOpen = errors.New("logger: handler circuit open")
+
+type BreakerOption func(*breakerOptions)
+
+type breakerOptions struct {
+	threshold int
+	cooldown  time.Duration
+	timeout   time.Duration
+	spool     slog.Handler
+}
+
+// WithBreakerThreshold opens the circuit after n consecutive failed
+// records. Default: 5.
+func WithBreakerThreshold(n int) BreakerOption {
+	return func(opts *breakerOptions) {
+		opts.threshold = n
+	}
+}
+
+// WithBreakerCooldown sets how long the circuit stays open before a single
+// record probes the sink again. Default: 10s.
+func WithBreakerCooldown(d time.Duration) BreakerOption {
+	return func(opts *breakerOptions) {
+		opts.cooldown = d
+	}
+}
+
+// WithBreakerTimeout counts a record the sink takes longer than d to handle
+// as failed as soon as d passed. Until that call returns, later records
+// skip the sink right away and are dropped or spooled; the caller of the
+// stuck call still waits for the sink. Zero disables it. Default: 1s.
+func WithBreakerTimeout(d time.Duration) BreakerOption {
+	return func(opts *breakerOptions) {
+		opts.timeout = d
+	}
+}
+
+// WithBreakerSpool passes records to h instead of dropping them while the
+// circuit is open, e.g. a handler writing to a local file.
+func WithBreakerSpool(h slog.Handler) BreakerOption {
+	return func(opts *breakerOptions) {
+		opts.spool = h
+	}
+}
+
+func BreakerOptions(options ...BreakerOption) *breakerOptions {
+	opts := &breakerOptions{
+		threshold: 5,
+		cooldown:  10 * time.Second,
+		timeout:   time.Second,
+	}
+	for _, opt := range options {
+		opt(opts)
+	}
+	return opts
+}
+
+// BreakerHandler guards a sink, e.g. a network handler, with a circuit
+// breaker: after threshold consecutive failed or timed-out records the
+// circuit opens and records are dropped, or spooled, without calling the
+// sink until the cooldown passed and a probe record succeeds. A hung sink
+// thus blocks only the calls in flight when it hung instead of every log
+// call.
+type BreakerHandler struct {
+	slog.Handler
+	spool slog.Handler
+	core  *breakerCore
+}
+
+type breakerCore struct {
+	opts    *breakerOptions
+	breaker *breaker
+	stuck   atomic.Int64
+	dropped atomic.Uint64
+}
+
+// h := logger.NewBreakerHandler(tcpHandler, logger.WithBreakerSpool(fileHandler))
+func NewBreakerHandler(h slog.Handler, options ...BreakerOption) *BreakerHandler {
+	opts := BreakerOptions(options...)
+	return &BreakerHandler{
+		Handler: h,
+		spool:   opts.spool,
+		core: &breakerCore{
+			opts:    opts,
+			breaker: newBreaker(opts.threshold, opts.cooldown),
+		},
+	}
+}
+
+// State returns BreakerClosed, BreakerOpen or BreakerHalfOpen.
+func (h *BreakerHandler) State() string {
+	return h.core.breaker.State()
+}
+
+// Dropped returns the number of records dropped without a spool.
+func (h *BreakerHandler) Dropped() uint64 {
+	return h.core.dropped.Load()
+}
+
+func (h *BreakerHandler) Handle(ctx context.Context, r slog.Record) error {
+	c := h.core
+	if !c.breaker.allow() {
+		return h.drop(ctx, r, ErrHandlerOpen)
+	}
+	if c.opts.timeout <= 0 {
+		err := h.Handler.Handle(ctx, r)
+		c.breaker.done(err == nil)
+		return err
+	}
+	if c.stuck.Load() > 0 {
+		c.breaker.done(false)
+		return h.drop(ctx, r, ErrWriteTimeout)
+	}
+
+	// The watchdog counts the call as failed once it overran the timeout,
+	// so the breaker opens while the sink still hangs.
+	var state atomic.Int32
+	t := time.AfterFunc(c.opts.timeout, func() {
+		if state.CompareAndSwap(callRunning, callTimedOut) {
+			c.stuck.Add(1)
+			c.breaker.done(false)
+		}
+	})
+	err := h.Handler.Handle(ctx, r)
+	if state.CompareAndSwap(callRunning, callDone) {
+		t.Stop()
+		c.breaker.done(err == nil)
+		return err
+	}
+	c.stuck.Add(-1)
+	return ErrWriteTimeout
+}
+
+// States of a sink call watched by BreakerHandler.
+const (
+	callRunning int32 = iota
+	callDone
+	callTimedOut
+)
+
+// drop spools r, or counts it as dropped and returns err.
+func (h *BreakerHandler) drop(ctx context.Context, r slog.Record, err error) error {
+	if h.spool == nil {
+		h.core.dropped.Add(1)
+		return err
+	}
+	if !h.spool.Enabled(ctx, r.Level) {
+		return nil
+	}
+	return h.spool.Handle(ctx, r)
+}
+
+func (h *BreakerHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	spool := h.spool
+	if spool != nil {
+		spool = spool.WithAttrs(attrs)
+	}
+	return &BreakerHandler{Handler: h.Handler.WithAttrs(attrs), spool: spool, core: h.core}
+}
+
+func (h *BreakerHandler) WithGroup(name string) slog.Handler {
+	spool := h.spool
+	if spool != nil {
+		spool = spool.WithGroup(name)
+	}
+	return &BreakerHandler{Handler: h.Handler.WithGroup(name), spool: spool, core: h.core}
+}
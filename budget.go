@@ -0,0 +1,57 @@
+package logger
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// DebugBudgetHandler lets records below INFO through until they add up to
+// budget bytes in a minute, then suppresses them for the rest of the minute
+// after writing one WARN notice. Records at INFO and above are unaffected,
+// so debug logging can be left enabled without saturating disks or
+// ingestion quotas.
+type DebugBudgetHandler struct {
+	slog.Handler
+	base  slog.Handler
+	quota *windowQuota
+}
+
+// logger.NewDebugBudgetHandler(h, 10<<20)
+func NewDebugBudgetHandler(h slog.Handler, budget int64) *DebugBudgetHandler {
+	return &DebugBudgetHandler{Handler: h, base: h, quota: newWindowQuota(time.Minute, 0, budget)}
+}
+
+func (h *DebugBudgetHandler) Handle(ctx context.Context, r slog.Record) error {
+	if r.Level >= slog.LevelInfo || forced(ctx, r) {
+		return h.Handler.Handle(ctx, r)
+	}
+
+	now := r.Time
+	if now.IsZero() {
+		now = time.Now()
+	}
+	ok, first := h.quota.allow(now, recordSize(r))
+	if first {
+		notice := slog.NewRecord(now, slog.LevelWarn, "debug log budget exceeded", 0)
+		notice.AddAttrs(
+			slog.Int64("budget_bytes", h.quota.bytes),
+			slog.Duration("window", h.quota.window),
+		)
+		if err := h.base.Handle(ctx, notice); err != nil {
+			return err
+		}
+	}
+	if !ok {
+		return nil
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h *DebugBudgetHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &DebugBudgetHandler{Handler: h.Handler.WithAttrs(attrs), base: h.base, quota: h.quota}
+}
+
+func (h *DebugBudgetHandler) WithGroup(name string) slog.Handler {
+	return &DebugBudgetHandler{Handler: h.Handler.WithGroup(name), base: h.base, quota: h.quota}
+}
@@ -0,0 +1,203 @@
+package logger
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+type BufferOption func(*bufferOptions)
+
+type bufferOptions struct {
+	interval   time.Duration
+	size       int
+	flushLevel slog.Level
+}
+
+// WithFlushInterval sets how often buffered output is written.
+// Default: 1s.
+func WithFlushInterval(d time.Duration) BufferOption {
+	return func(opts *bufferOptions) {
+		opts.interval = d
+	}
+}
+
+// WithBufferSize sets how many bytes are buffered before they are written.
+// Default: 64 KiB.
+func WithBufferSize(n int) BufferOption {
+	return func(opts *bufferOptions) {
+		opts.size = n
+	}
+}
+
+// WithFlushLevel makes BufferedHandler write the buffer right after
+// records at level or above. Default: slog.LevelError.
+func WithFlushLevel(level slog.Level) BufferOption {
+	return func(opts *bufferOptions) {
+		opts.flushLevel = level
+	}
+}
+
+func BufferOptions(options ...BufferOption) *bufferOptions {
+	opts := &bufferOptions{
+		interval:   time.Second,
+		size:       64 << 10,
+		flushLevel: slog.LevelError,
+	}
+	for _, opt := range options {
+		opt(opts)
+	}
+	return opts
+}
+
+// BufferedWriter buffers writes to w and writes them every flush interval,
+// when the buffer is full and on Flush, cutting syscalls for high-volume
+// output. A failed write is returned by the next Write or Flush.
+type BufferedWriter struct {
+	w    io.Writer
+	opts *bufferOptions
+
+	mu     sync.Mutex
+	buf    []byte
+	err    error
+	closed bool
+	stop   chan struct{}
+	done   chan struct{}
+}
+
+// w := logger.NewBufferedWriter(conn, logger.WithFlushInterval(500*time.Millisecond))
+// defer w.Close()
+func NewBufferedWriter(w io.Writer, options ...BufferOption) *BufferedWriter {
+	bw := &BufferedWriter{
+		w:    w,
+		opts: BufferOptions(options...),
+		stop: make(chan struct{}),
+		done: make(chan struct{}),
+	}
+	go bw.flushLoop()
+	return bw
+}
+
+func (w *BufferedWriter) flushLoop() {
+	defer close(w.done)
+
+	t := time.NewTicker(w.opts.interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-w.stop:
+			return
+		case <-t.C:
+			w.mu.Lock()
+			w.err = errors.Join(w.err, w.flush())
+			w.mu.Unlock()
+		}
+	}
+}
+
+func (w *BufferedWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.closed {
+		return 0, errors.New("logger: buffered writer closed")
+	}
+	if err := w.err; err != nil {
+		w.err = nil
+		return 0, err
+	}
+	w.buf = append(w.buf, p...)
+	if len(w.buf) >= w.opts.size {
+		return len(p), w.flush()
+	}
+	return len(p), nil
+}
+
+// flush writes the buffer. w.mu must be held.
+func (w *BufferedWriter) flush() error {
+	if len(w.buf) == 0 {
+		return nil
+	}
+	_, err := w.w.Write(w.buf)
+	w.buf = w.buf[:0]
+	return err
+}
+
+// Flush writes the buffer now.
+func (w *BufferedWriter) Flush() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	err := errors.Join(w.err, w.flush())
+	w.err = nil
+	return err
+}
+
+// Close writes the buffer, stops the flusher and closes w if it is an
+// io.Closer.
+func (w *BufferedWriter) Close() error {
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return nil
+	}
+	w.closed = true
+	w.mu.Unlock()
+
+	close(w.stop)
+	<-w.done
+
+	err := w.Flush()
+	if c, ok := w.w.(io.Closer); ok {
+		err = errors.Join(err, c.Close())
+	}
+	return err
+}
+
+// BufferedHandler encodes records with a handler writing to a
+// BufferedWriter, and writes the buffer right after records at the flush
+// level or above, so errors show up promptly while the rest is batched.
+type BufferedHandler struct {
+	slog.Handler
+	w *BufferedWriter
+}
+
+// NewBufferedHandler buffers the output of the handler built by newHandler:
+//
+//	h := logger.NewBufferedHandler(os.Stdout, func(w io.Writer) slog.Handler {
+//		return slog.NewJSONHandler(w, nil)
+//	}, logger.WithFlushLevel(slog.LevelWarn))
+//	defer h.Close()
+func NewBufferedHandler(w io.Writer, newHandler func(io.Writer) slog.Handler, options ...BufferOption) *BufferedHandler {
+	bw := NewBufferedWriter(w, options...)
+	return &BufferedHandler{Handler: newHandler(bw), w: bw}
+}
+
+func (h *BufferedHandler) Handle(ctx context.Context, r slog.Record) error {
+	err := h.Handler.Handle(ctx, r)
+	if r.Level >= h.w.opts.flushLevel {
+		err = errors.Join(err, h.w.Flush())
+	}
+	return err
+}
+
+func (h *BufferedHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &BufferedHandler{Handler: h.Handler.WithAttrs(attrs), w: h.w}
+}
+
+func (h *BufferedHandler) WithGroup(name string) slog.Handler {
+	return &BufferedHandler{Handler: h.Handler.WithGroup(name), w: h.w}
+}
+
+// Flush writes the buffered records.
+func (h *BufferedHandler) Flush() error {
+	return h.w.Flush()
+}
+
+// Close writes the buffered records and closes the writer.
+func (h *BufferedHandler) Close() error {
+	return h.w.Close()
+}
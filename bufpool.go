@@ -0,0 +1,86 @@
+package logger
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+const (
+	minBufferSize = 256
+	// maxPooledBuffer is the largest buffer kept for reuse, so a rare huge
+	// record does not pin its memory.
+	maxPooledBuffer = 64 << 10
+	bufferBuckets   = 10
+	// bufferSamples is how many sizes are observed between updates of the
+	// size new buffers get.
+	bufferSamples = 1024
+)
+
+// bufferPool pools encoding buffers and sizes new ones to the 90th
+// percentile of the encoded record sizes seen recently, so services logging
+// consistently large records do not grow every new buffer.
+type bufferPool struct {
+	pool sync.Pool
+	// counts[i] counts sizes up to minBufferSize<<i; halved on every update
+	// so old sizes fade out.
+	counts [bufferBuckets]atomic.Uint32
+	n      atomic.Uint32
+	size   atomic.Int64
+}
+
+func newBufferPool() *bufferPool {
+	p := &bufferPool{}
+	p.size.Store(1024)
+	return p
+}
+
+func (p *bufferPool) get() *[]byte {
+	if bp, ok := p.pool.Get().(*[]byte); ok {
+		return bp
+	}
+	b := make([]byte, 0, p.size.Load())
+	return &b
+}
+
+func (p *bufferPool) put(bp *[]byte) {
+	p.observe(len(*bp))
+	if cap(*bp) > maxPooledBuffer {
+		return
+	}
+	*bp = (*bp)[:0]
+	p.pool.Put(bp)
+}
+
+func (p *bufferPool) observe(size int) {
+	i := 0
+	for i < bufferBuckets-1 && size > minBufferSize<<i {
+		i++
+	}
+	p.counts[i].Add(1)
+	if p.n.Add(1)%bufferSamples == 0 {
+		p.update()
+	}
+}
+
+// update sets the size of new buffers to the p90 bucket and decays the
+// counts. Concurrent observations may be lost, which only blurs the
+// estimate.
+func (p *bufferPool) update() {
+	var counts [bufferBuckets]uint32
+	var total uint32
+	for i := range p.counts {
+		counts[i] = p.counts[i].Load()
+		total += counts[i]
+	}
+	var seen uint32
+	for i, c := range counts {
+		seen += c
+		if seen*10 >= total*9 {
+			p.size.Store(int64(min(minBufferSize<<i, maxPooledBuffer)))
+			break
+		}
+	}
+	for i := range p.counts {
+		p.counts[i].Store(counts[i] / 2)
+	}
+}
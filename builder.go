@@ -0,0 +1,265 @@
+package logger
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+)
+
+// Builder assembles a handler writing to several outputs. It starts with
+// os.Stdout: WithWriter adds an output next to the existing ones, while
+// WithOnlyWriter, WithOnlyWriters, WithoutStdout and WithoutDefaultWriter
+// replace or remove them.
+type Builder struct {
+	writers   []builderWriter
+	options   []Option
+	health    []HealthOption
+	sampling  []SamplingOption
+	mws       []Middleware
+	asDefault bool
+}
+
+// builderWriter is an output and the options overriding the Builder's for
+// it.
+type builderWriter struct {
+	w       io.Writer
+	options []Option
+	seed    bool
+}
+
+// h := logger.NewBuilder(logger.WithJSON(true)).WithOnlyWriter(file).Build()
+func NewBuilder(options ...Option) *Builder {
+	return &Builder{writers: []builderWriter{{w: os.Stdout, seed: true}}, options: options}
+}
+
+// WithWriter adds w to the outputs. options override the Builder's for w,
+// e.g. its format, level or time format:
+//
+//	logger.NewBuilder(logger.WithFastText(true)).WithWriter(file, logger.WithJSON(true), logger.WithLevel("warn"))
+//
+// A level given for w is fixed: SetLevel does not change it.
+func (b *Builder) WithWriter(w io.Writer, options ...Option) *Builder {
+	b.writers = append(b.writers, builderWriter{w: w, options: options})
+	return b
+}
+
+// WithOnlyWriter replaces all outputs, including os.Stdout, with w.
+func (b *Builder) WithOnlyWriter(w io.Writer, options ...Option) *Builder {
+	b.writers = []builderWriter{{w: w, options: options}}
+	return b
+}
+
+// WithOnlyWriters replaces all outputs, including os.Stdout, with ws.
+func (b *Builder) WithOnlyWriters(ws ...io.Writer) *Builder {
+	b.writers = make([]builderWriter, 0, len(ws))
+	for _, w := range ws {
+		b.writers = append(b.writers, builderWriter{w: w})
+	}
+	return b
+}
+
+// WithoutDefaultWriter removes the os.Stdout output NewBuilder starts
+// with, keeping os.Stdout if it was added with WithWriter.
+//
+//	logger.NewBuilder().WithoutDefaultWriter().WithWriter(file).Build()
+func (b *Builder) WithoutDefaultWriter() *Builder {
+	writers := b.writers[:0]
+	for _, w := range b.writers {
+		if !w.seed {
+			writers = append(writers, w)
+		}
+	}
+	b.writers = writers
+	return b
+}
+
+// WithoutStdout removes os.Stdout from the outputs.
+func (b *Builder) WithoutStdout() *Builder {
+	writers := b.writers[:0]
+	for _, w := range b.writers {
+		if w.w != io.Writer(os.Stdout) {
+			writers = append(writers, w)
+		}
+	}
+	b.writers = writers
+	return b
+}
+
+// WithWriterHealth wraps every output in a HealthWriter, so a hung or
+// failing one cannot block logging.
+func (b *Builder) WithWriterHealth(options ...HealthOption) *Builder {
+	b.health = append(b.health, options...)
+	if b.health == nil {
+		b.health = []HealthOption{}
+	}
+	return b
+}
+
+// WithSampling wraps the handler in a SamplingHandler.
+func (b *Builder) WithSampling(options ...SamplingOption) *Builder {
+	b.sampling = append(b.sampling, options...)
+	if b.sampling == nil {
+		b.sampling = []SamplingOption{}
+	}
+	return b
+}
+
+// WithMiddleware wraps the handler in mws, as Chain does. Sampling from
+// WithSampling runs first.
+func (b *Builder) WithMiddleware(mws ...Middleware) *Builder {
+	b.mws = append(b.mws, mws...)
+	return b
+}
+
+func (b *Builder) WithOptions(options ...Option) *Builder {
+	b.options = append(b.options, options...)
+	return b
+}
+
+// Build returns the handler; with no outputs left it discards records. Its
+// level follows SetLevel unless WithLevelVar is given; WithLevel sets the
+// package level as NewLogger does. The handler flushes the outputs with
+// Flush; the outputs themselves stay open, as they belong to the caller.
+func (b *Builder) Build() *BuiltHandler {
+	opts := LoggerOptions(b.options...)
+	opts.globalLevel = true
+	opts.setGlobalLevel()
+
+	built := &BuiltHandler{}
+	var shared []io.Writer
+	var sinks []Sink
+	for i, bw := range b.writers {
+		w := bw.w
+		built.outputs = append(built.outputs, w)
+		if b.health != nil {
+			w = NewHealthWriter(w, b.health...)
+		}
+		if len(bw.options) == 0 {
+			shared = append(shared, w)
+			continue
+		}
+		wopts := LoggerOptions(append(b.options[:len(b.options):len(b.options)], bw.options...)...)
+		wopts.globalLevel = !LoggerOptions(bw.options...).levelSet
+		sinks = append(sinks, Sink{Name: fmt.Sprintf("writer%d", i), Handler: newHandler(w, wopts).Handler})
+	}
+
+	var w io.Writer
+	switch len(shared) {
+	case 0:
+		w = io.Discard
+	case 1:
+		w = shared[0]
+	default:
+		w = fanoutWriter(shared)
+	}
+	var h slog.Handler = newHandler(w, opts)
+	if len(sinks) > 0 {
+		// the sinks get the bare handlers, so the caller and context attrs
+		// are added once, by a ContextHandler around them all
+		if len(shared) > 0 {
+			sinks = append([]Sink{{Name: "shared", Handler: h.(ContextHandler).Handler}}, sinks...)
+		}
+		h = opts.contextHandler(NewMultiHandler(sinks...), nil)
+	}
+	h = Chain(h, b.mws...)
+	if b.sampling != nil {
+		h = NewSamplingHandler(h, b.sampling...)
+	}
+	built.Handler = h
+	return built
+}
+
+// BuiltHandler is the handler returned by Builder.Build and Flags.Build.
+type BuiltHandler struct {
+	slog.Handler
+	outputs []io.Writer
+	closers []io.Closer
+}
+
+// Flush flushes the outputs that are Flushers and syncs the files.
+func (h *BuiltHandler) Flush() error {
+	var errs []error
+	for _, w := range h.outputs {
+		if w != io.Writer(os.Stdout) && w != io.Writer(os.Stderr) {
+			errs = append(errs, flush(w))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Close flushes the outputs and closes those opened for the handler, like
+// the -log.file of Flags, and unregisters it from Shutdown. Writers passed
+// to the Builder are left open.
+func (h *BuiltHandler) Close() error {
+	unregisterCloser(h)
+	err := h.Flush()
+	return errors.Join(err, closeAll(h.closers))
+}
+
+// AsDefault makes BuildLogger install the logger with slog.SetDefault and
+// register its handler for Flush and Shutdown.
+func (b *Builder) AsDefault() *Builder {
+	b.asDefault = true
+	return b
+}
+
+// BuildLogger returns a logger using the handler of Build, set as the
+// default logger with AsDefault. It fails for nil outputs.
+//
+//	l, err := logger.NewProductionBuilder().WithOnlyWriter(file).AsDefault().BuildLogger()
+func (b *Builder) BuildLogger() (*slog.Logger, error) {
+	for i, w := range b.writers {
+		if w.w == nil {
+			return nil, fmt.Errorf("logger: builder output %d is nil", i)
+		}
+	}
+	built := b.Build()
+	l := slog.New(built)
+	if b.asDefault {
+		RegisterCloser(built)
+		slog.SetDefault(l)
+	}
+	return l, nil
+}
+
+// Clone returns a copy of b, so a base configuration can be forked per
+// subsystem without the forks changing each other.
+//
+//	base := logger.NewBuilder(logger.WithJSON(true))
+//	audit := base.Clone().WithOnlyWriter(auditFile).Build()
+func (b *Builder) Clone() *Builder {
+	b2 := *b
+	b2.writers = append([]builderWriter(nil), b.writers...)
+	b2.options = b.options[:len(b.options):len(b.options)]
+	if b.health != nil {
+		b2.health = b.health[:len(b.health):len(b.health)]
+	}
+	if b.sampling != nil {
+		b2.sampling = b.sampling[:len(b.sampling):len(b.sampling)]
+	}
+	b2.mws = b.mws[:len(b.mws):len(b.mws)]
+	return &b2
+}
+
+// fanoutWriter writes to every writer even if some fail, unlike
+// io.MultiWriter, so one broken output does not silence the others.
+type fanoutWriter []io.Writer
+
+func (ws fanoutWriter) Write(p []byte) (int, error) {
+	var errs []error
+	for _, w := range ws {
+		n, err := w.Write(p)
+		if err == nil && n < len(p) {
+			err = io.ErrShortWrite
+		}
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) > 0 {
+		return 0, errors.Join(errs...)
+	}
+	return len(p), nil
+}
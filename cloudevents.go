@@ -0,0 +1,218 @@
+package logger
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// Keys of the attrs CloudEventsHandler uses for the event type, source and
+// subject instead of putting them in the event data.
+const (
+	EventTypeKey    string = "ce_type"
+	EventSourceKey  string = "ce_source"
+	EventSubjectKey string = "ce_subject"
+)
+
+type CloudEventsOption func(*cloudEventsOptions)
+
+type cloudEventsOptions struct {
+	source    string
+	eventType string
+	level     slog.Leveler
+}
+
+// WithEventSource sets the source of events without a ce_source attr.
+func WithEventSource(source string) CloudEventsOption {
+	return func(opts *cloudEventsOptions) {
+		opts.source = source
+	}
+}
+
+// WithEventType sets the type of events without a ce_type attr.
+// Default: "log.record".
+func WithEventType(eventType string) CloudEventsOption {
+	return func(opts *cloudEventsOptions) {
+		opts.eventType = eventType
+	}
+}
+
+func WithEventLevel(level slog.Leveler) CloudEventsOption {
+	return func(opts *cloudEventsOptions) {
+		opts.level = level
+	}
+}
+
+func CloudEventsOptions(options ...CloudEventsOption) *cloudEventsOptions {
+	opts := &cloudEventsOptions{
+		source:    "logger",
+		eventType: "log.record",
+		level:     slog.LevelInfo,
+	}
+	for _, opt := range options {
+		opt(opts)
+	}
+	return opts
+}
+
+// CloudEventsHandler writes every record as a CloudEvents 1.0 JSON event,
+// one per line, for platforms ingesting logs as events (Knative,
+// EventBridge). The record's level, message and attrs become the event
+// data; ce_type, ce_source and ce_subject attrs, of the record or from
+// WithAttrs, set the event attributes.
+type CloudEventsHandler struct {
+	w      io.Writer
+	mu     *sync.Mutex
+	opts   *cloudEventsOptions
+	event  eventAttrs
+	prefix []byte
+	groups []string
+	opened int
+}
+
+// eventAttrs are the event attributes set by attrs.
+type eventAttrs struct {
+	source, eventType, subject string
+}
+
+// set sets the event attribute of a and reports whether a is one.
+func (e *eventAttrs) set(a slog.Attr) bool {
+	switch a.Key {
+	case EventTypeKey:
+		e.eventType = a.Value.String()
+	case EventSourceKey:
+		e.source = a.Value.String()
+	case EventSubjectKey:
+		e.subject = a.Value.String()
+	default:
+		return false
+	}
+	return true
+}
+
+// logger.NewCloudEventsHandler(os.Stdout, logger.WithEventSource("/orders"))
+// slog.Info("order placed", logger.EventTypeKey, "com.example.order.placed", logger.EventSubjectKey, id)
+func NewCloudEventsHandler(w io.Writer, options ...CloudEventsOption) *CloudEventsHandler {
+	opts := CloudEventsOptions(options...)
+	return &CloudEventsHandler{w: w, mu: &sync.Mutex{}, opts: opts, event: eventAttrs{source: opts.source, eventType: opts.eventType}}
+}
+
+func (h *CloudEventsHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= h.opts.level.Level()
+}
+
+func eventAttr(a slog.Attr) bool {
+	return a.Key == EventTypeKey || a.Key == EventSourceKey || a.Key == EventSubjectKey
+}
+
+func (h *CloudEventsHandler) Handle(_ context.Context, r slog.Record) error {
+	event := h.event
+	data := false
+	r.Attrs(func(a slog.Attr) bool {
+		if !event.set(a) {
+			data = data || !emptyAttrs([]slog.Attr{a})
+		}
+		return true
+	})
+	bp := jsonBufPool.get()
+	buf := (*bp)[:0]
+
+	buf = append(buf, `{"specversion":"1.0","id":"`...)
+	buf = append(buf, eventID()...)
+	buf = append(buf, `","source":`...)
+	buf = appendJSONString(buf, event.source)
+	buf = append(buf, `,"type":`...)
+	buf = appendJSONString(buf, event.eventType)
+	if event.subject != "" {
+		buf = append(buf, `,"subject":`...)
+		buf = appendJSONString(buf, event.subject)
+	}
+	if !r.Time.IsZero() {
+		buf = append(buf, `,"time":"`...)
+		buf = r.Time.AppendFormat(buf, time.RFC3339Nano)
+		buf = append(buf, '"')
+	}
+	buf = append(buf, `,"datacontenttype":"application/json","data":{"level":`...)
+	buf = appendJSONString(buf, r.Level.String())
+	buf = append(buf, `,"msg":`...)
+	buf = appendJSONString(buf, r.Message)
+
+	buf = append(buf, h.prefix...)
+	closing := h.opened
+	if data {
+		for _, g := range h.groups[h.opened:] {
+			buf = appendJSONSep(buf)
+			buf = appendJSONString(buf, g)
+			buf = append(buf, ":{"...)
+		}
+		closing = len(h.groups)
+		r.Attrs(func(a slog.Attr) bool {
+			if !eventAttr(a) {
+				buf = appendJSONAttr(buf, a)
+			}
+			return true
+		})
+	}
+	for i := 0; i < closing; i++ {
+		buf = append(buf, '}')
+	}
+	buf = append(buf, "}}\n"...)
+
+	h.mu.Lock()
+	_, err := h.w.Write(buf)
+	h.mu.Unlock()
+
+	*bp = buf
+	jsonBufPool.put(bp)
+	return err
+}
+
+// WithAttrs encodes attrs once, opening the groups from WithGroup that
+// were still empty. Event attributes are kept for the envelope instead.
+func (h *CloudEventsHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	h2 := *h
+	data := make([]slog.Attr, 0, len(attrs))
+	for _, a := range attrs {
+		if !h2.event.set(a) {
+			data = append(data, a)
+		}
+	}
+	if emptyAttrs(data) {
+		return &h2
+	}
+	prefix := make([]byte, len(h.prefix), len(h.prefix)+64)
+	copy(prefix, h.prefix)
+	for _, g := range h.groups[h.opened:] {
+		prefix = appendJSONSep(prefix)
+		prefix = appendJSONString(prefix, g)
+		prefix = append(prefix, ":{"...)
+	}
+	h2.opened = len(h.groups)
+	for _, a := range data {
+		prefix = appendJSONAttr(prefix, a)
+	}
+	h2.prefix = prefix
+	return &h2
+}
+
+func (h *CloudEventsHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	h2 := *h
+	h2.groups = append(h.groups[:len(h.groups):len(h.groups)], name)
+	return &h2
+}
+
+func eventID() string {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return fmt.Sprint(time.Now().UnixNano())
+	}
+	return hex.EncodeToString(b[:])
+}
@@ -0,0 +1,33 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"testing"
+	"testing/slogtest"
+)
+
+func TestCloudEventsHandlerConformance(t *testing.T) {
+	var buf bytes.Buffer
+	h := NewCloudEventsHandler(&buf)
+
+	results := func() []map[string]any {
+		var ms []map[string]any
+		for _, line := range bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte{'\n'}) {
+			var event map[string]any
+			if err := json.Unmarshal(line, &event); err != nil {
+				t.Fatalf("%s: %v", line, err)
+			}
+			m, _ := event["data"].(map[string]any)
+			if ts, ok := event["time"]; ok {
+				m[slog.TimeKey] = ts
+			}
+			ms = append(ms, m)
+		}
+		return ms
+	}
+	if err := slogtest.TestHandler(h, results); err != nil {
+		t.Error(err)
+	}
+}
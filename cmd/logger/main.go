@@ -1,3 +1,5 @@
+//go:build !logger_lite && !logger_nogorm && !logger_nogokit
+
 package main
 
 import (
@@ -20,7 +22,7 @@ func main() {
 	}
 
 	{
-		logger := logger.NewGormLogger("info")
+		logger := logger.NewGormLoggerWith(slog.Default(), "info")
 		logger.Info(context.Background(), "init %s %s %s %s", "logger", "gorm.io/gorm/logger", "format", "json")
 		// {"time":"2024-04-26T21:11:28+05:00","level":"INFO","msg":"init logger gorm.io/gorm/logger format json","caller":"logger/main.go:24"}
 	}
@@ -36,7 +38,7 @@ func main() {
 	}
 
 	{
-		logger := logger.NewGormLogger("info")
+		logger := logger.NewGormLoggerWith(slog.Default(), "info")
 		logger.Info(context.Background(), "init %s %s %s %s", "logger", "gorm.io/gorm/logger", "format", "text")
 		// time=2024-04-26T21:11:28+05:00 level=INFO msg="init logger gorm.io/gorm/logger format text" caller=logger/main.go:40
 	}
@@ -0,0 +1,83 @@
+// Command logstats prints a per-minute level histogram of JSON or text logs
+// of this logger as sparklines, for a quick look at error bursts:
+//
+//	logstats -minutes 120 app.log
+//	kubectl logs deploy/api | logstats -json
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/isauran/logger"
+	"github.com/isauran/logger/decode"
+)
+
+func main() {
+	minutes := flag.Int("minutes", 60, "minutes to show, up to the newest record")
+	asJSON := flag.Bool("json", false, "print the histogram as JSON")
+	flag.Parse()
+
+	hist := logger.NewLevelHistogram(*minutes)
+	if flag.NArg() == 0 {
+		add(hist, os.Stdin, "stdin")
+	}
+	for _, name := range flag.Args() {
+		f, err := os.Open(name)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		add(hist, f, name)
+		f.Close()
+	}
+
+	report := hist.Report()
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		_ = enc.Encode(report)
+		return
+	}
+	if len(report.Minutes) == 0 {
+		fmt.Println("no records")
+		return
+	}
+	first, last := report.Minutes[0].Minute, report.Minutes[len(report.Minutes)-1].Minute
+	fmt.Printf("%s to %s, one column per minute\n", first.Format("2006-01-02 15:04"), last.Format("15:04"))
+	for _, level := range []string{logger.LevelDebug, logger.LevelInfo, logger.LevelWarn, logger.LevelError, logger.LevelFatal, logger.LevelPanic} {
+		line, ok := report.Sparklines[level]
+		if !ok {
+			continue
+		}
+		total := 0
+		for _, m := range report.Minutes {
+			total += m.Counts[level]
+		}
+		fmt.Printf("%-5s %s %d\n", level, line, total)
+	}
+}
+
+// add counts the records of r, skipping lines that do not decode.
+func add(hist *logger.LevelHistogram, r io.Reader, name string) {
+	d := decode.NewDecoder(r)
+	for {
+		e, err := d.Decode()
+		if err == io.EOF {
+			return
+		}
+		var syntax *decode.SyntaxError
+		if errors.As(err, &syntax) {
+			continue
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", name, err)
+			return
+		}
+		hist.Add(e.Time, e.Level)
+	}
+}
@@ -0,0 +1,98 @@
+package logger
+
+import (
+	"io"
+	"log/slog"
+	"os"
+	"sort"
+)
+
+// NoColorEnv disables colored output when set to any value, see
+// https://no-color.org.
+const NoColorEnv string = "NO_COLOR"
+
+// LevelStyle is how FastTextHandler writes a level: Color is an ANSI SGR
+// parameter such as "31" for red or "1;35" for bold magenta, and Icon
+// starts the line.
+type LevelStyle struct {
+	Color string `json:"color,omitempty"`
+	Icon  string `json:"icon,omitempty"`
+}
+
+// DefaultLevelStyles colors the standard levels. Records at custom levels
+// get the style of the closest standard level below them.
+var DefaultLevelStyles = map[slog.Level]LevelStyle{
+	slog.LevelDebug: {Color: "90"},
+	slog.LevelInfo:  {Color: "32"},
+	slog.LevelWarn:  {Color: "33"},
+	slog.LevelError: {Color: "31"},
+}
+
+// WithLevelStyles styles the levels written by FastTextHandler, e.g. to
+// brand development output. Colors are only written to terminals, and never
+// if NO_COLOR is set; icons are always written.
+//
+//	logger.NewLogger(os.Stdout, logger.WithFastText(true), logger.WithLevelStyles(map[slog.Level]logger.LevelStyle{
+//		slog.LevelInfo:  {Color: "36", Icon: "ℹ"},
+//		slog.LevelError: {Color: "1;31", Icon: "✖"},
+//	}))
+func WithLevelStyles(styles map[slog.Level]LevelStyle) Option {
+	return func(opts *loggerOptions) {
+		opts.levelStyles = styles
+	}
+}
+
+type levelStyle struct {
+	level slog.Level
+	LevelStyle
+}
+
+// levelStyles returns styles ordered by level, without colors unless w is
+// a terminal and NO_COLOR is unset.
+func levelStyles(styles map[slog.Level]LevelStyle, w io.Writer) []levelStyle {
+	color := colorEnabled(w)
+	out := make([]levelStyle, 0, len(styles))
+	for level, s := range styles {
+		if !color {
+			s.Color = ""
+		}
+		out = append(out, levelStyle{level: level, LevelStyle: s})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].level < out[j].level })
+	return out
+}
+
+func colorEnabled(w io.Writer) bool {
+	if _, ok := os.LookupEnv(NoColorEnv); ok {
+		return false
+	}
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	fi, err := f.Stat()
+	return err == nil && fi.Mode()&os.ModeCharDevice != 0
+}
+
+// levelStyleFor returns the closest style at or below level.
+func levelStyleFor(styles []levelStyle, level slog.Level) LevelStyle {
+	var style LevelStyle
+	for _, s := range styles {
+		if s.level > level {
+			break
+		}
+		style = s.LevelStyle
+	}
+	return style
+}
+
+func appendLevel(buf []byte, style LevelStyle, level slog.Level) []byte {
+	if style.Color == "" {
+		return append(buf, levelName(level)...)
+	}
+	buf = append(buf, "\x1b["...)
+	buf = append(buf, style.Color...)
+	buf = append(buf, 'm')
+	buf = append(buf, levelName(level)...)
+	return append(buf, "\x1b[0m"...)
+}
@@ -1,7 +1,9 @@
 package logger
 
 import (
-	"strings"
+	"log/slog"
+	"strconv"
+	"sync/atomic"
 	"time"
 )
 
@@ -12,12 +14,35 @@ const (
 	LevelError string = "ERROR"
 )
 
+// Option configures NewLogger, Builder and the adapters. NewGoKitLogger and
+// NewStdLogger only honour WithLogger and WithCallerSkip, NewGormLoggerWith
+// only WithCallerSkip; NewLogger and Builder ignore WithLogger. Options a
+// constructor does not honour are ignored.
 type Option func(*loggerOptions)
 
 type loggerOptions struct {
-	json       bool
-	level      string
-	timeFormat string
+	json           bool
+	fastText       bool
+	level          string
+	timeFormat     string
+	transforms     []Transform
+	callerSkip     int
+	fallbackSkip   int
+	sourceFunc     func(s *slog.Source) string
+	sourceFunction string
+	extractors     []namedExtractor
+	logger         *slog.Logger
+	schema         *RecordSchema
+	location       *time.Location
+	replaceAttr    []ReplaceAttrFunc
+	keys           KeyNames
+	levelStyles    map[slog.Level]LevelStyle
+	epoch          string
+	levelVar       *slog.LevelVar
+	levelSet       bool
+	globalLevel    bool
+	attrOrder      *attrOrder
+	multiline      bool
 }
 
 func WithJSON(json bool) Option {
@@ -26,19 +51,19 @@ func WithJSON(json bool) Option {
 	}
 }
 
+// WithFastText selects FastTextHandler for text output, trading group
+// nesting and key rewriting for minimal per-record overhead.
+func WithFastText(fastText bool) Option {
+	return func(opts *loggerOptions) {
+		opts.fastText = fastText
+	}
+}
+
 func WithLevel(level string) Option {
 	return func(opts *loggerOptions) {
-		if strings.Contains(strings.ToUpper(level), LevelDebug) {
-			opts.level = LevelDebug
-		}
-		if strings.Contains(strings.ToUpper(level), LevelInfo) {
-			opts.level = LevelInfo
-		}
-		if strings.Contains(strings.ToUpper(level), LevelWarn) {
-			opts.level = LevelWarn
-		}
-		if strings.Contains(strings.ToUpper(level), LevelError) {
-			opts.level = LevelError
+		if name := levelString(level); name != "" {
+			opts.level = name
+			opts.levelSet = true
 		}
 	}
 }
@@ -49,11 +74,119 @@ func WithTimeFormat(layout string) Option {
 	}
 }
 
+// WithTimeZone writes timestamps in loc regardless of the host's local
+// time zone.
+//
+//	loc, _ := time.LoadLocation("Europe/Berlin")
+//	logger.NewLogger(os.Stdout, logger.WithTimeZone(loc))
+func WithTimeZone(loc *time.Location) Option {
+	return func(opts *loggerOptions) {
+		opts.location = loc
+	}
+}
+
+// WithUTC writes timestamps in UTC.
+func WithUTC() Option {
+	return WithTimeZone(time.UTC)
+}
+
+// Units of WithTimeEpoch.
+const (
+	EpochSeconds string = "s"
+	EpochMillis  string = "ms"
+	EpochNanos   string = "ns"
+)
+
+// WithTimeEpoch writes timestamps as integer Unix time in unit, EpochSeconds,
+// EpochMillis or EpochNanos, for analytics tools expecting numeric time. It
+// takes precedence over WithTimeFormat.
+//
+//	logger.NewLogger(os.Stdout, logger.WithJSON(true), logger.WithTimeEpoch(logger.EpochMillis))
+func WithTimeEpoch(unit string) Option {
+	return func(opts *loggerOptions) {
+		switch unit {
+		case EpochSeconds, EpochMillis, EpochNanos:
+			opts.epoch = unit
+		}
+	}
+}
+
+// appendEpoch appends t as Unix time in unit.
+func appendEpoch(buf []byte, t time.Time, unit string) []byte {
+	return strconv.AppendInt(buf, unixTime(t, unit), 10)
+}
+
+func unixTime(t time.Time, unit string) int64 {
+	switch unit {
+	case EpochMillis:
+		return t.UnixMilli()
+	case EpochNanos:
+		return t.UnixNano()
+	default:
+		return t.Unix()
+	}
+}
+
+// WithMultilineErrors makes FastTextHandler write the lines after the first
+// of multi-line error attrs, such as stack traces, indented below the
+// record instead of escaped.
+func WithMultilineErrors() Option {
+	return func(opts *loggerOptions) {
+		opts.multiline = true
+	}
+}
+
+// WithTransforms applies rules to the attrs of records. It panics if a
+// rule is invalid; rules read from configuration are validated by
+// LoadTransforms.
+//
+//	logger.NewLogger(os.Stdout, logger.WithTransforms(rules...))
+func WithTransforms(rules ...Transform) Option {
+	for _, t := range rules {
+		if err := t.validate(); err != nil {
+			panic("logger: " + err.Error())
+		}
+	}
+	return func(opts *loggerOptions) {
+		opts.transforms = append(opts.transforms, rules...)
+	}
+}
+
+// WithCallerSkip skips n additional stack frames when reporting the caller,
+// for code that logs through its own wrapper functions. It applies to
+// NewLogger, NewGoKitLogger, NewGormLoggerWith and NewStdLogger.
+func WithCallerSkip(n int) Option {
+	return func(opts *loggerOptions) {
+		opts.callerSkip = n
+	}
+}
+
+// WithCallerFallbackSkip skips n more frames when a record carries no PC,
+// e.g. one built with slog.NewRecord and a zero pc, and the caller is found
+// by walking the stack from ContextHandler instead: one per handler wrapping
+// it.
+func WithCallerFallbackSkip(n int) Option {
+	return func(opts *loggerOptions) {
+		opts.fallbackSkip = n
+	}
+}
+
+// WithLogger makes an adapter log to l instead of the default logger, so
+// libraries and tests can use isolated pipelines. It applies to
+// NewGoKitLogger, NewStdLogger and the deprecated NewGormLogger.
+func WithLogger(l *slog.Logger) Option {
+	return func(opts *loggerOptions) {
+		opts.logger = l
+	}
+}
+
 func LoggerOptions(options ...Option) *loggerOptions {
 	opts := &loggerOptions{
 		json:       false,
 		level:      LevelInfo,
 		timeFormat: time.RFC3339,
+		sourceFunc: shortSource,
+		keys:       KeyNames{}.withDefaults(),
 	}
 
 	for _, opt := range options {
@@ -61,3 +194,40 @@ func LoggerOptions(options ...Option) *loggerOptions {
 	}
 	return opts
 }
+
+// controlConfig is the configuration of the last NewLogger call, served at
+// /config.
+type controlConfig struct {
+	Level      string      `json:"level"`
+	Format     string      `json:"format"`
+	TimeFormat string      `json:"time_format"`
+	TimeZone   string      `json:"time_zone,omitempty"`
+	TimeEpoch  string      `json:"time_epoch,omitempty"`
+	CallerSkip int         `json:"caller_skip,omitempty"`
+	Transforms []Transform `json:"transforms,omitempty"`
+}
+
+var currentConfig atomic.Pointer[controlConfig]
+
+func (opts *loggerOptions) config() *controlConfig {
+	format := "text"
+	switch {
+	case opts.json:
+		format = "json"
+	case opts.fastText:
+		format = "fasttext"
+	}
+	var zone string
+	if opts.location != nil {
+		zone = opts.location.String()
+	}
+	return &controlConfig{
+		Level:      opts.level,
+		TimeZone:   zone,
+		TimeEpoch:  opts.epoch,
+		Format:     format,
+		TimeFormat: opts.timeFormat,
+		CallerSkip: opts.callerSkip,
+		Transforms: opts.transforms,
+	}
+}
@@ -0,0 +1,120 @@
+package logger
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+// Config is the JSON logging configuration read by LoadConfig and
+// WatchConfig:
+//
+//	{"level": "info", "levels": "payments.db=debug", "format": "json", "outputs": ["stdout", "/var/log/app.log"]}
+type Config struct {
+	Level      string   `json:"level,omitempty"`
+	Levels     string   `json:"levels,omitempty"`
+	Format     string   `json:"format,omitempty"`
+	TimeFormat string   `json:"time_format,omitempty"`
+	TimeZone   string   `json:"time_zone,omitempty"`
+	TimeEpoch  string   `json:"time_epoch,omitempty"`
+	Outputs    []string `json:"outputs,omitempty"`
+}
+
+// LoadConfig reads and validates the Config in the file at path.
+func LoadConfig(path string) (*Config, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var c Config
+	if err := json.Unmarshal(b, &c); err != nil {
+		return nil, fmt.Errorf("logger: config %s: %w", path, err)
+	}
+	if _, err := c.Options(); err != nil {
+		return nil, fmt.Errorf("logger: config %s: %w", path, err)
+	}
+	return &c, nil
+}
+
+// Options returns the options configured by c. Outputs and Levels are not
+// options; see WatchConfig.
+func (c *Config) Options() ([]Option, error) {
+	var options []Option
+	if c.Level != "" {
+		if levelString(c.Level) == "" {
+			return nil, fmt.Errorf("unknown level %q", c.Level)
+		}
+		options = append(options, WithLevel(c.Level))
+	}
+	if _, err := ParseLevelDirectives(c.Levels); err != nil {
+		return nil, err
+	}
+	switch c.Format {
+	case "", "text":
+	case "json":
+		options = append(options, WithJSON(true))
+	case "fasttext":
+		options = append(options, WithFastText(true))
+	default:
+		return nil, fmt.Errorf("unknown format %q", c.Format)
+	}
+	if c.TimeFormat != "" {
+		options = append(options, WithTimeFormat(c.TimeFormat))
+	}
+	if c.TimeZone != "" {
+		loc, err := time.LoadLocation(c.TimeZone)
+		if err != nil {
+			return nil, err
+		}
+		options = append(options, WithTimeZone(loc))
+	}
+	switch c.TimeEpoch {
+	case "", EpochSeconds, EpochMillis, EpochNanos:
+		if c.TimeEpoch != "" {
+			options = append(options, WithTimeEpoch(c.TimeEpoch))
+		}
+	default:
+		return nil, fmt.Errorf("unknown time epoch %q", c.TimeEpoch)
+	}
+	return options, nil
+}
+
+// open opens the outputs of c: "stdout", "stderr" or files appended to.
+// Without outputs it writes to stdout. The closers close the files.
+func (c *Config) open() (io.Writer, []io.Closer, error) {
+	var ws fanoutWriter
+	var closers []io.Closer
+	for _, out := range c.Outputs {
+		switch out {
+		case "stdout":
+			ws = append(ws, os.Stdout)
+		case "stderr":
+			ws = append(ws, os.Stderr)
+		default:
+			f, err := os.OpenFile(out, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+			if err != nil {
+				return nil, nil, errors.Join(err, closeAll(closers))
+			}
+			ws = append(ws, f)
+			closers = append(closers, f)
+		}
+	}
+	switch len(ws) {
+	case 0:
+		return os.Stdout, nil, nil
+	case 1:
+		return ws[0], closers, nil
+	}
+	return ws, closers, nil
+}
+
+func closeAll(closers []io.Closer) error {
+	var errs []error
+	for _, c := range closers {
+		errs = append(errs, c.Close())
+	}
+	return errors.Join(errs...)
+}
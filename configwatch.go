@@ -0,0 +1,124 @@
+package logger
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"os"
+	"time"
+)
+
+type WatchOption func(*watchOptions)
+
+type watchOptions struct {
+	interval time.Duration
+	options  []Option
+}
+
+// WithWatchInterval sets how often the config file is checked for
+// changes. Default: 2s.
+func WithWatchInterval(d time.Duration) WatchOption {
+	return func(opts *watchOptions) {
+		opts.interval = d
+	}
+}
+
+// WithWatchOptions sets the options the config file is applied on top of,
+// e.g. WithExtractor.
+func WithWatchOptions(options ...Option) WatchOption {
+	return func(opts *watchOptions) {
+		opts.options = append(opts.options, options...)
+	}
+}
+
+func WatchOptions(options ...WatchOption) *watchOptions {
+	opts := &watchOptions{interval: 2 * time.Second}
+	for _, opt := range options {
+		opt(opts)
+	}
+	return opts
+}
+
+// WatchConfig sets the default logger from the Config in the file at path
+// and polls the file until ctx is done, applying changes of level, format
+// and outputs to the default logger and every logger derived from it. An
+// invalid config is reported and the running one kept. Records in flight
+// finish on the old outputs before they are closed. Once ctx is done the
+// current outputs are closed too, so l should not be used any more.
+//
+//	l, err := logger.WatchConfig(ctx, "/etc/app/logging.json")
+func WatchConfig(ctx context.Context, path string, options ...WatchOption) (*slog.Logger, error) {
+	opts := WatchOptions(options...)
+
+	c, err := LoadConfig(path)
+	if err != nil {
+		return nil, err
+	}
+	handler, closers, err := c.apply(opts.options)
+	if err != nil {
+		return nil, err
+	}
+	h := NewSwapHandler(handler)
+	l := slog.New(h)
+	slog.SetDefault(l)
+
+	st, _ := os.Stat(path)
+	go func() {
+		t := time.NewTicker(opts.interval)
+		defer t.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				_ = closeAll(closers)
+				return
+			case <-t.C:
+			}
+			cur, err := os.Stat(path)
+			if err != nil || st != nil && cur.ModTime().Equal(st.ModTime()) && cur.Size() == st.Size() {
+				continue
+			}
+			st = cur
+
+			var next []io.Closer
+			c, err := LoadConfig(path)
+			if err == nil {
+				handler, next, err = c.apply(opts.options)
+			}
+			if err != nil {
+				l.Warn("log config not reloaded", "path", path, "err", err)
+				continue
+			}
+			h.Swap(handler)
+			if err := closeAll(closers); err != nil {
+				l.Warn("close old log outputs", "err", err)
+			}
+			closers = next
+			l.Info("log config reloaded", "path", path)
+		}
+	}()
+	return l, nil
+}
+
+// apply sets the package level and named levels of c and returns a handler
+// following them, and the closers of its outputs.
+func (c *Config) apply(base []Option) (slog.Handler, []io.Closer, error) {
+	options, err := c.Options()
+	if err != nil {
+		return nil, nil, err
+	}
+	w, closers, err := c.open()
+	if err != nil {
+		return nil, nil, err
+	}
+	opts := LoggerOptions(append(base[:len(base):len(base)], options...)...)
+	opts.globalLevel = true
+	if opts.levelVar == nil {
+		globalLevel.Set(levelOf(opts.level))
+	}
+	if err := ApplyLevelDirectives(c.Levels); err != nil {
+		return nil, nil, errors.Join(err, closeAll(closers))
+	}
+	currentConfig.Store(opts.config())
+	return newHandler(w, opts), closers, nil
+}
@@ -0,0 +1,139 @@
+package logger
+
+import (
+	"context"
+	"log/slog"
+)
+
+const (
+	RequestIDKey string = "request_id"
+	TraceIDKey   string = "trace_id"
+	SpanIDKey    string = "span_id"
+)
+
+type requestIDKey struct{}
+
+type traceKey struct{}
+
+type traceFlagsKey struct{}
+
+type attrsKey struct{}
+
+// ctx = logger.ContextWithRequestID(ctx, r.Header.Get("X-Request-Id"))
+// slog.InfoContext(ctx, "handled") // ... request_id=...
+func ContextWithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, slog.String(RequestIDKey, id))
+}
+
+func RequestIDFromContext(ctx context.Context) string {
+	if a, ok := ctx.Value(requestIDKey{}).(slog.Attr); ok {
+		return a.Value.String()
+	}
+	return ""
+}
+
+func ContextWithTrace(ctx context.Context, traceID, spanID string) context.Context {
+	return context.WithValue(ctx, traceKey{}, []slog.Attr{
+		slog.String(TraceIDKey, traceID),
+		slog.String(SpanIDKey, spanID),
+	})
+}
+
+func TraceFromContext(ctx context.Context) (traceID, spanID string) {
+	attrs, _ := ctx.Value(traceKey{}).([]slog.Attr)
+	for _, a := range attrs {
+		switch a.Key {
+		case TraceIDKey:
+			traceID = a.Value.String()
+		case SpanIDKey:
+			spanID = a.Value.String()
+		}
+	}
+	return
+}
+
+// ContextWithTraceFlags sets the W3C trace flags InjectContext propagates
+// with the trace, e.g. 0x01 for a sampled trace. ExtractContext sets them
+// from the traceparent header.
+func ContextWithTraceFlags(ctx context.Context, flags byte) context.Context {
+	return context.WithValue(ctx, traceFlagsKey{}, flags)
+}
+
+func TraceFlagsFromContext(ctx context.Context) (flags byte, ok bool) {
+	flags, ok = ctx.Value(traceFlagsKey{}).(byte)
+	return
+}
+
+// ContextWithAttrs accumulates attrs that are added to every record logged
+// with the returned context.
+func ContextWithAttrs(ctx context.Context, attrs ...slog.Attr) context.Context {
+	prev := AttrsFromContext(ctx)
+	all := make([]slog.Attr, 0, len(prev)+len(attrs))
+	all = append(append(all, prev...), attrs...)
+	return context.WithValue(ctx, attrsKey{}, all)
+}
+
+func AttrsFromContext(ctx context.Context) []slog.Attr {
+	attrs, _ := ctx.Value(attrsKey{}).([]slog.Attr)
+	return attrs
+}
+
+type callerSkipKey struct{}
+
+// ContextWithCaller reports the caller skip frames above the caller of
+// ContextWithCaller as the source of records logged with the returned
+// context, for helpers that log on behalf of their caller:
+//
+//	func audit(ctx context.Context, msg string) {
+//		slog.InfoContext(logger.ContextWithCaller(ctx, 1), msg) // caller=the caller of audit
+//	}
+func ContextWithCaller(ctx context.Context, skip int) context.Context {
+	return SourceContext(ctx, CallerSource(2+skip))
+}
+
+// ContextWithCallerSkip makes ContextHandler skip n more frames when it
+// resolves the source of records logged with the returned context. Unlike
+// ContextWithCaller the frame is resolved by each log call, so one context
+// can be shared by the calls of a wrapper.
+func ContextWithCallerSkip(ctx context.Context, n int) context.Context {
+	return context.WithValue(ctx, callerSkipKey{}, n)
+}
+
+// SourceFromContext returns the source set with SourceContext or
+// ContextWithCaller.
+func SourceFromContext(ctx context.Context) (*slog.Source, bool) {
+	a, ok := ctx.Value(sourceKey{}).(slog.Attr)
+	if !ok {
+		return nil, false
+	}
+	s, ok := a.Value.Any().(*slog.Source)
+	return s, ok && s != nil
+}
+
+type noSampleKey struct{}
+
+// NoSample marks records logged with ctx to bypass sampling, rate limits and
+// quotas, e.g. for startup banners or billing events. The same applies to a
+// record carrying a "force"=true attr.
+func NoSample(ctx context.Context) context.Context {
+	return context.WithValue(ctx, noSampleKey{}, true)
+}
+
+const ForceKey string = "force"
+
+func forced(ctx context.Context, r slog.Record) bool {
+	if ctx != nil {
+		if v, _ := ctx.Value(noSampleKey{}).(bool); v {
+			return true
+		}
+	}
+	force := false
+	r.Attrs(func(a slog.Attr) bool {
+		if a.Key == ForceKey && a.Value.Kind() == slog.KindBool && a.Value.Bool() {
+			force = true
+			return false
+		}
+		return true
+	})
+	return force
+}
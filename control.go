@@ -0,0 +1,229 @@
+//go:build !logger_lite && !logger_nohttp
+
+package logger
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+func init() {
+	registerFeature("control")
+}
+
+type ControlOption func(*controlOptions)
+
+type controlOptions struct {
+	auth   func(http.Handler) http.Handler
+	ring   *RingHandler
+	hist   *LevelHistogram
+	redact RedactProfile
+	views  map[string]func() any
+}
+
+// WithControlAuth wraps the control endpoint with an auth middleware.
+// Without one, only loopback clients are served.
+func WithControlAuth(auth func(http.Handler) http.Handler) ControlOption {
+	return func(opts *controlOptions) {
+		opts.auth = auth
+	}
+}
+
+// WithControlRing serves the records of ring at /records.
+func WithControlRing(ring *RingHandler) ControlOption {
+	return func(opts *controlOptions) {
+		opts.ring = ring
+	}
+}
+
+// WithControlHistogram serves the report of hist at /levels.
+func WithControlHistogram(hist *LevelHistogram) ControlOption {
+	return func(opts *controlOptions) {
+		opts.hist = hist
+	}
+}
+
+// WithControlRedact redacts the keys of p in the records served at /records.
+func WithControlRedact(p RedactProfile) ControlOption {
+	return func(opts *controlOptions) {
+		opts.redact = p
+	}
+}
+
+// WithControlView serves the JSON encoding of fn() at /name, e.g. sink
+// health or a metrics snapshot.
+func WithControlView(name string, fn func() any) ControlOption {
+	return func(opts *controlOptions) {
+		opts.views[strings.Trim(name, "/")] = fn
+	}
+}
+
+func ControlOptions(options ...ControlOption) *controlOptions {
+	opts := &controlOptions{views: make(map[string]func() any)}
+	for _, opt := range options {
+		opt(opts)
+	}
+	return opts
+}
+
+// ControlBearerAuth accepts requests carrying "Authorization: Bearer token".
+func ControlBearerAuth(token string) func(http.Handler) http.Handler {
+	want := []byte("Bearer " + token)
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			got := []byte(r.Header.Get("Authorization"))
+			if subtle.ConstantTimeCompare(got, want) != 1 {
+				http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// NewControlHandler serves read-only JSON views of the logging pipeline:
+// /config, /errors (RecentErrors), /records (WithControlRing), /levels
+// (WithControlHistogram) and one per WithControlView. / lists the available
+// views. /records takes the query parameters level, attr (key=value,
+// repeatable), q (substring), since (a duration or RFC 3339 time) and
+// limit, e.g.
+// /records?level=WARN&attr=request_id=42&since=5m.
+//
+//	mux.Handle("/debug/logger/", http.StripPrefix("/debug/logger", logger.NewControlHandler(
+//		logger.WithControlAuth(logger.ControlBearerAuth(token)),
+//		logger.WithControlRing(ring),
+//		logger.WithControlView("dlq", func() any { return client.DeadLetterStats() }),
+//	)))
+func NewControlHandler(options ...ControlOption) http.Handler {
+	opts := ControlOptions(options...)
+
+	views := map[string]func(*http.Request) (any, error){
+		"config": func(*http.Request) (any, error) { return currentConfig.Load(), nil },
+		"errors": func(*http.Request) (any, error) { return RecentErrors(), nil },
+	}
+	if opts.ring != nil {
+		ring, redact := opts.ring, NewRedactHandler(nil, opts.redact)
+		views["records"] = func(r *http.Request) (any, error) {
+			q, err := ringQuery(r.URL.Query())
+			if err != nil {
+				return nil, err
+			}
+			records := ring.Records()
+			for i := range records {
+				records[i].Attrs = redact.redactMap(records[i].Attrs)
+			}
+			// Filter after redacting, so queries cannot probe redacted values.
+			return q.Filter(records), nil
+		}
+	}
+	if opts.hist != nil {
+		hist := opts.hist
+		views["levels"] = func(*http.Request) (any, error) { return hist.Report(), nil }
+	}
+	for name, fn := range opts.views {
+		fn := fn
+		views[name] = func(*http.Request) (any, error) { return fn(), nil }
+	}
+
+	names := make([]string, 0, len(views))
+	for name := range views {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var h http.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+
+		name := strings.Trim(r.URL.Path, "/")
+		var v any = names
+		if name != "" {
+			fn, ok := views[name]
+			if !ok {
+				http.NotFound(w, r)
+				return
+			}
+			var err error
+			if v, err = fn(r); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-store")
+		if err := json.NewEncoder(w).Encode(v); err != nil {
+			slog.Default().Warn("control endpoint: encode view", "view", name, "err", err)
+		}
+	})
+
+	if opts.auth != nil {
+		return opts.auth(h)
+	}
+	return loopbackOnly(h)
+}
+
+// ringQuery parses the query parameters of /records.
+func ringQuery(values url.Values) (RingQuery, error) {
+	q := RingQuery{Text: values.Get("q")}
+	if s := values.Get("level"); s != "" {
+		var level slog.Level
+		if err := level.UnmarshalText([]byte(s)); err != nil {
+			return q, err
+		}
+		q.Level = &level
+	}
+	for _, kv := range values["attr"] {
+		k, v, ok := strings.Cut(kv, "=")
+		if !ok {
+			return q, fmt.Errorf("attr %q: want key=value", kv)
+		}
+		if q.Attrs == nil {
+			q.Attrs = map[string]string{}
+		}
+		q.Attrs[k] = v
+	}
+	if s := values.Get("since"); s != "" {
+		if d, err := time.ParseDuration(s); err == nil {
+			q.Since = time.Now().Add(-d)
+		} else if q.Since, err = time.Parse(time.RFC3339, s); err != nil {
+			return q, fmt.Errorf("since %q: want a duration or RFC 3339 time", s)
+		}
+	}
+	if s := values.Get("limit"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil {
+			return q, fmt.Errorf("limit %q: %w", s, err)
+		}
+		q.Limit = n
+	}
+	return q, nil
+}
+
+func loopbackOnly(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host, _, err := net.SplitHostPort(r.RemoteAddr)
+		if err != nil {
+			host = r.RemoteAddr
+		}
+		addr, err := netip.ParseAddr(host)
+		if err != nil || !addr.Unmap().IsLoopback() {
+			http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,169 @@
+//go:build !logger_lite && !logger_nofile
+
+package logger
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+)
+
+func init() {
+	registerFeature("crash")
+}
+
+// CrashHandler persists the first limit records of a run, and up to limit
+// later ERROR records, to a small state file. If the previous run did not
+// call Close, a WARN record summarizing its persisted records and exit reason
+// is written on startup, which makes crash loops visible in containers that
+// lose their previous output.
+type CrashHandler struct {
+	slog.Handler
+	core *crashCore
+}
+
+type crashCore struct {
+	limit int
+
+	mu      sync.Mutex
+	f       *os.File
+	written int
+	errors  int
+}
+
+type crashLine struct {
+	Started time.Time `json:"started,omitempty"`
+	PID     int       `json:"pid,omitempty"`
+	Time    time.Time `json:"time,omitempty"`
+	Level   string    `json:"level,omitempty"`
+	Msg     string    `json:"msg,omitempty"`
+	Exit    string    `json:"exit,omitempty"`
+	Clean   bool      `json:"clean,omitempty"`
+}
+
+// h, err := logger.NewCrashHandler(h, "/var/lib/app/last-run.jsonl", 50)
+// defer h.Close()
+func NewCrashHandler(h slog.Handler, path string, limit int) (*CrashHandler, error) {
+	prev, err := readCrashState(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	c := &crashCore{limit: limit, f: f}
+	if err := c.write(crashLine{Started: time.Now(), PID: os.Getpid()}); err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	if len(prev) > 0 && !prev[len(prev)-1].Clean {
+		_ = h.Handle(context.Background(), crashSummary(prev))
+	}
+	return &CrashHandler{Handler: h, core: c}, nil
+}
+
+func readCrashState(path string) ([]crashLine, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var lines []crashLine
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		var l crashLine
+		if json.Unmarshal(sc.Bytes(), &l) == nil {
+			lines = append(lines, l)
+		}
+	}
+	return lines, sc.Err()
+}
+
+func crashSummary(prev []crashLine) slog.Record {
+	r := slog.NewRecord(time.Now(), slog.LevelWarn, "previous run exited uncleanly", 0)
+
+	var records []string
+	exit := "unknown"
+	for _, l := range prev {
+		switch {
+		case !l.Started.IsZero():
+			r.AddAttrs(slog.Int("prev_pid", l.PID), slog.Time("prev_started", l.Started))
+		case l.Exit != "":
+			exit = l.Exit
+		case l.Msg != "" || l.Level != "":
+			records = append(records, fmt.Sprintf("%s %s %s", l.Time.Format(time.RFC3339), l.Level, l.Msg))
+		}
+	}
+	r.AddAttrs(slog.String("exit_reason", exit), slog.Any("last_records", records))
+	return r
+}
+
+func (c *crashCore) write(l crashLine) error {
+	b, err := json.Marshal(l)
+	if err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.f == nil {
+		return os.ErrClosed
+	}
+	_, err = c.f.Write(append(b, '\n'))
+	return err
+}
+
+func (h *CrashHandler) Handle(ctx context.Context, r slog.Record) error {
+	c := h.core
+	c.mu.Lock()
+	persist := c.written < c.limit
+	if !persist && r.Level >= slog.LevelError && c.errors < c.limit {
+		persist = true
+		c.errors++
+	}
+	c.written++
+	c.mu.Unlock()
+
+	if persist {
+		_ = c.write(crashLine{Time: r.Time, Level: r.Level.String(), Msg: r.Message})
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h *CrashHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &CrashHandler{Handler: h.Handler.WithAttrs(attrs), core: h.core}
+}
+
+func (h *CrashHandler) WithGroup(name string) slog.Handler {
+	return &CrashHandler{Handler: h.Handler.WithGroup(name), core: h.core}
+}
+
+// Exit records why the process is about to stop, e.g. from a signal handler
+// or a recovered panic, to be reported by the next run.
+func (h *CrashHandler) Exit(reason string) {
+	_ = h.core.write(crashLine{Exit: reason})
+}
+
+// Close marks the run as cleanly finished.
+func (h *CrashHandler) Close() error {
+	err := h.core.write(crashLine{Clean: true})
+
+	h.core.mu.Lock()
+	defer h.core.mu.Unlock()
+	if h.core.f == nil {
+		return err
+	}
+	err = errors.Join(err, h.core.f.Close())
+	h.core.f = nil
+	return err
+}
@@ -0,0 +1,19 @@
+//go:build logger_lite || logger_nofile
+
+package logger
+
+import "log/slog"
+
+type CrashHandler struct {
+	slog.Handler
+}
+
+func NewCrashHandler(h slog.Handler, path string, limit int) (*CrashHandler, error) {
+	return nil, ErrUnsupported
+}
+
+func (h *CrashHandler) Exit(reason string) {}
+
+func (h *CrashHandler) Close() error {
+	return ErrUnsupported
+}
@@ -0,0 +1,37 @@
+package logger
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+const (
+	DeadlineRemainingKey string = "deadline_remaining_ms"
+	CancelledKey         string = "cancelled"
+)
+
+// WithContextDeadline adds a "ctx" group to records logged with a context
+// that has a deadline or is done, with the milliseconds left until the
+// deadline and whether the context is cancelled, to help debug timeouts:
+//
+//	ctx.deadline_remaining_ms=-12.5 ctx.cancelled=true
+func WithContextDeadline() Option {
+	return WithExtractor("deadline", DeadlineExtractor)
+}
+
+// DeadlineExtractor is the Extractor added by WithContextDeadline.
+func DeadlineExtractor(ctx context.Context) []slog.Attr {
+	deadline, ok := ctx.Deadline()
+	err := ctx.Err()
+	if !ok && err == nil {
+		return nil
+	}
+
+	attrs := make([]any, 0, 2)
+	if ok {
+		attrs = append(attrs, slog.Float64(DeadlineRemainingKey, float64(time.Until(deadline).Microseconds())/1e3))
+	}
+	attrs = append(attrs, slog.Bool(CancelledKey, err != nil))
+	return []slog.Attr{slog.Group("ctx", attrs...)}
+}
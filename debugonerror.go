@@ -0,0 +1,111 @@
+package logger
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+)
+
+type debugBufferKey struct{}
+
+// debugBuffer keeps the last records of a context that the downstream
+// handler would not have written.
+type debugBuffer struct {
+	mu      sync.Mutex
+	entries []debugEntry
+	next    int
+	full    bool
+}
+
+type debugEntry struct {
+	handler slog.Handler
+	record  slog.Record
+}
+
+// ContextWithDebugBuffer makes DebugOnErrorHandler keep the last size
+// records below ERROR logged with the returned context, e.g. one request.
+//
+//	ctx = logger.ContextWithDebugBuffer(r.Context(), 100)
+func ContextWithDebugBuffer(ctx context.Context, size int) context.Context {
+	return context.WithValue(ctx, debugBufferKey{}, &debugBuffer{entries: make([]debugEntry, max(size, 1))})
+}
+
+func (b *debugBuffer) push(h slog.Handler, r slog.Record) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.entries[b.next] = debugEntry{handler: h, record: r}
+	b.next = (b.next + 1) % len(b.entries)
+	if b.next == 0 {
+		b.full = true
+	}
+}
+
+// drain returns the kept records, oldest first, and empties the buffer.
+func (b *debugBuffer) drain() []debugEntry {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var out []debugEntry
+	if b.full {
+		out = append(out, b.entries[b.next:]...)
+	}
+	out = append(out, b.entries[:b.next]...)
+	clear(b.entries)
+	b.next, b.full = 0, false
+	return out
+}
+
+// DebugOnErrorHandler writes records below the level of h only when an
+// ERROR record is logged with the same context: they are kept in the
+// context's debug buffer and flushed in order before the error, giving the
+// debug context of a failure without always-on debug logging. Records
+// logged without a ContextWithDebugBuffer context are handled by h as usual.
+type DebugOnErrorHandler struct {
+	slog.Handler
+}
+
+// logger.NewDebugOnErrorHandler(h)
+func NewDebugOnErrorHandler(h slog.Handler) *DebugOnErrorHandler {
+	return &DebugOnErrorHandler{Handler: h}
+}
+
+func (h *DebugOnErrorHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	if _, ok := ctx.Value(debugBufferKey{}).(*debugBuffer); ok {
+		return true
+	}
+	return h.Handler.Enabled(ctx, level)
+}
+
+func (h *DebugOnErrorHandler) Handle(ctx context.Context, r slog.Record) error {
+	buf, _ := ctx.Value(debugBufferKey{}).(*debugBuffer)
+	if buf == nil {
+		return h.Handler.Handle(ctx, r)
+	}
+
+	if r.Level >= slog.LevelError {
+		var errs []error
+		for _, e := range buf.drain() {
+			if err := e.handler.Handle(ctx, e.record); err != nil {
+				errs = append(errs, err)
+			}
+		}
+		errs = append(errs, h.Handler.Handle(ctx, r))
+		return errors.Join(errs...)
+	}
+
+	if h.Handler.Enabled(ctx, r.Level) {
+		return h.Handler.Handle(ctx, r)
+	}
+	buf.push(h.Handler, r.Clone())
+	return nil
+}
+
+func (h *DebugOnErrorHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &DebugOnErrorHandler{Handler: h.Handler.WithAttrs(attrs)}
+}
+
+func (h *DebugOnErrorHandler) WithGroup(name string) slog.Handler {
+	return &DebugOnErrorHandler{Handler: h.Handler.WithGroup(name)}
+}
@@ -0,0 +1,318 @@
+// Package decode parses the JSON and text (logfmt) output of this logger back
+// into records with typed attrs, for tools working on archived logs:
+//
+//	d := decode.NewDecoder(f)
+//	for {
+//		e, err := d.Decode()
+//		if err == io.EOF {
+//			break
+//		}
+//		...
+//	}
+package decode
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Keys of the built-in fields, as written by logger.NewLogger.
+const (
+	TimeKey    string = "time"
+	LevelKey   string = "level"
+	MessageKey string = "msg"
+	CallerKey  string = "caller"
+)
+
+// Entry is a decoded log line. Attrs keep their order; JSON objects become
+// groups, numbers become int64 or float64, and text values that parse as
+// bools, numbers, durations or RFC 3339 times are typed accordingly.
+type Entry struct {
+	Time    time.Time
+	Level   slog.Level
+	Message string
+	Caller  string
+	Attrs   []slog.Attr
+}
+
+// Record returns e as a slog.Record, with Caller as a "caller" attr.
+func (e Entry) Record() slog.Record {
+	r := slog.NewRecord(e.Time, e.Level, e.Message, 0)
+	r.AddAttrs(e.Attrs...)
+	if e.Caller != "" {
+		r.AddAttrs(slog.String(CallerKey, e.Caller))
+	}
+	return r
+}
+
+// SyntaxError reports a line that could not be decoded.
+type SyntaxError struct {
+	Line int
+	Err  error
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("decode: line %d: %v", e.Line, e.Err)
+}
+
+func (e *SyntaxError) Unwrap() error {
+	return e.Err
+}
+
+type Decoder struct {
+	sc   *bufio.Scanner
+	line int
+}
+
+func NewDecoder(r io.Reader) *Decoder {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 0, 64<<10), 16<<20)
+	return &Decoder{sc: sc}
+}
+
+// Decode returns the next entry, skipping blank lines, or io.EOF. Lines
+// that fail to parse yield a *SyntaxError; decoding can continue after it.
+func (d *Decoder) Decode() (Entry, error) {
+	for d.sc.Scan() {
+		d.line++
+		line := bytes.TrimSpace(d.sc.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		e, err := ParseLine(line)
+		if err != nil {
+			return Entry{}, &SyntaxError{Line: d.line, Err: err}
+		}
+		return e, nil
+	}
+	if err := d.sc.Err(); err != nil {
+		return Entry{}, err
+	}
+	return Entry{}, io.EOF
+}
+
+// ParseLine decodes a single JSON or logfmt line.
+func ParseLine(line []byte) (Entry, error) {
+	line = bytes.TrimSpace(line)
+	if len(line) > 0 && line[0] == '{' {
+		return parseJSON(line)
+	}
+	return parseText(line)
+}
+
+func parseJSON(line []byte) (Entry, error) {
+	dec := json.NewDecoder(bytes.NewReader(line))
+	dec.UseNumber()
+	if _, err := dec.Token(); err != nil {
+		return Entry{}, err
+	}
+	attrs, err := jsonObject(dec)
+	if err != nil {
+		return Entry{}, err
+	}
+	return entry(attrs), nil
+}
+
+func jsonObject(dec *json.Decoder) ([]slog.Attr, error) {
+	var attrs []slog.Attr
+	for dec.More() {
+		t, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		key, ok := t.(string)
+		if !ok {
+			return nil, fmt.Errorf("unexpected %v", t)
+		}
+		v, err := jsonValue(dec)
+		if err != nil {
+			return nil, err
+		}
+		attrs = append(attrs, slog.Attr{Key: key, Value: v})
+	}
+	if _, err := dec.Token(); err != nil {
+		return nil, err
+	}
+	return attrs, nil
+}
+
+func jsonValue(dec *json.Decoder) (slog.Value, error) {
+	t, err := dec.Token()
+	if err != nil {
+		return slog.Value{}, err
+	}
+	switch t := t.(type) {
+	case json.Delim:
+		switch t {
+		case '{':
+			attrs, err := jsonObject(dec)
+			if err != nil {
+				return slog.Value{}, err
+			}
+			return slog.GroupValue(attrs...), nil
+		case '[':
+			var items []any
+			for dec.More() {
+				v, err := jsonValue(dec)
+				if err != nil {
+					return slog.Value{}, err
+				}
+				items = append(items, v.Any())
+			}
+			if _, err := dec.Token(); err != nil {
+				return slog.Value{}, err
+			}
+			return slog.AnyValue(items), nil
+		}
+		return slog.Value{}, fmt.Errorf("unexpected %v", t)
+	case json.Number:
+		if n, err := t.Int64(); err == nil {
+			return slog.Int64Value(n), nil
+		}
+		f, err := t.Float64()
+		return slog.Float64Value(f), err
+	case string:
+		return slog.StringValue(t), nil
+	case bool:
+		return slog.BoolValue(t), nil
+	case nil:
+		return slog.AnyValue(nil), nil
+	}
+	return slog.Value{}, fmt.Errorf("unexpected %v", t)
+}
+
+func parseText(line []byte) (Entry, error) {
+	var attrs []slog.Attr
+	s := string(line)
+	for len(s) > 0 {
+		s = strings.TrimLeft(s, " ")
+		if s == "" {
+			break
+		}
+		eq := strings.IndexByte(s, '=')
+		if eq <= 0 {
+			return Entry{}, errors.New("expected key=value")
+		}
+		key := s[:eq]
+		s = s[eq+1:]
+
+		var (
+			raw    string
+			quoted bool
+		)
+		if strings.HasPrefix(s, `"`) {
+			end := quotedEnd(s)
+			if end < 0 {
+				return Entry{}, fmt.Errorf("unterminated value of %q", key)
+			}
+			v, err := strconv.Unquote(s[:end])
+			if err != nil {
+				return Entry{}, fmt.Errorf("value of %q: %w", key, err)
+			}
+			raw, quoted, s = v, true, s[end:]
+		} else {
+			end := strings.IndexByte(s, ' ')
+			if end < 0 {
+				end = len(s)
+			}
+			raw, s = s[:end], s[end:]
+		}
+
+		v := slog.StringValue(raw)
+		if !quoted {
+			v = textValue(raw)
+		}
+		attrs = append(attrs, slog.Attr{Key: key, Value: v})
+	}
+	return entry(attrs), nil
+}
+
+// quotedEnd returns the index just past the closing quote of the quoted
+// string at the start of s, or -1.
+func quotedEnd(s string) int {
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '"':
+			return i + 1
+		}
+	}
+	return -1
+}
+
+func textValue(s string) slog.Value {
+	if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return slog.Int64Value(n)
+	}
+	if f, err := strconv.ParseFloat(s, 64); err == nil {
+		return slog.Float64Value(f)
+	}
+	if b, err := strconv.ParseBool(s); err == nil && (s == "true" || s == "false") {
+		return slog.BoolValue(b)
+	}
+	if d, err := time.ParseDuration(s); err == nil {
+		return slog.DurationValue(d)
+	}
+	if t, err := time.Parse(time.RFC3339Nano, s); err == nil {
+		return slog.TimeValue(t)
+	}
+	return slog.StringValue(s)
+}
+
+// entry moves the built-in fields out of attrs.
+func entry(attrs []slog.Attr) Entry {
+	var e Entry
+	rest := attrs[:0]
+	for _, a := range attrs {
+		switch a.Key {
+		case TimeKey:
+			switch a.Value.Kind() {
+			case slog.KindTime:
+				e.Time = a.Value.Time()
+				continue
+			case slog.KindString:
+				if t, err := time.Parse(time.RFC3339Nano, a.Value.String()); err == nil {
+					e.Time = t
+					continue
+				}
+			}
+		case LevelKey:
+			if level, ok := parseLevel(a.Value.String()); ok {
+				e.Level = level
+				continue
+			}
+		case MessageKey:
+			e.Message = a.Value.String()
+			continue
+		case CallerKey:
+			e.Caller = a.Value.String()
+			continue
+		}
+		rest = append(rest, a)
+	}
+	e.Attrs = rest
+	return e
+}
+
+// parseLevel parses slog level names and FATAL and PANIC, written by
+// logger.Fatal and logger.Panic.
+func parseLevel(s string) (slog.Level, bool) {
+	switch s {
+	case "FATAL":
+		return slog.LevelError + 4, true
+	case "PANIC":
+		return slog.LevelError + 8, true
+	}
+	var level slog.Level
+	err := level.UnmarshalText([]byte(s))
+	return level, err == nil
+}
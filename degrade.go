@@ -0,0 +1,206 @@
+package logger
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/isauran/logger/replay"
+)
+
+// DegradeStep is a rung of the degradation ladder, from StepNormal to
+// StepErrorsOnly.
+type DegradeStep int
+
+const (
+	StepNormal DegradeStep = iota
+	StepDropDebug
+	StepDropInfo
+	StepSpool
+	StepErrorsOnly
+)
+
+func (s DegradeStep) String() string {
+	switch s {
+	case StepNormal:
+		return "normal"
+	case StepDropDebug:
+		return "drop_debug"
+	case StepDropInfo:
+		return "drop_info"
+	case StepSpool:
+		return "spool"
+	case StepErrorsOnly:
+		return "errors_only"
+	}
+	return "unknown"
+}
+
+// DegradePolicy configures DegradeHandler. The sink is failing when
+// Failures consecutive Handle calls return an error or take longer than
+// Slow; each time, the handler steps one rung down the ladder. After
+// Recover without failures it steps back up one rung. With a nil Spool the
+// StepSpool rung is skipped. Transitions are logged to Notify, not to the
+// degraded sink; a nil Notify writes them as text to os.Stderr.
+type DegradePolicy struct {
+	Failures int
+	Slow     time.Duration
+	Recover  time.Duration
+	Spool    io.Writer
+	Notify   slog.Handler
+}
+
+// DegradeHandler replaces blocking on a failing or slow sink with a
+// configurable ladder: drop DEBUG, then drop INFO, then write WARN and
+// above to Spool instead of the sink (in the replay envelope format, see
+// replay.Replay), then keep only ERROR. Transitions are logged to the
+// policy's Notify handler at WARN (degraded) or INFO (recovered).
+type DegradeHandler struct {
+	slog.Handler
+	spool slog.Handler
+	core  *degradeCore
+}
+
+type degradeCore struct {
+	policy DegradePolicy
+
+	mu       sync.Mutex
+	step     DegradeStep
+	failures int
+	changed  time.Time
+	onChange func(from, to DegradeStep)
+}
+
+//	logger.NewDegradeHandler(lokiHandler, logger.DegradePolicy{
+//		Failures: 5, Slow: 50 * time.Millisecond, Recover: time.Minute, Spool: spoolFile,
+//	})
+func NewDegradeHandler(h slog.Handler, policy DegradePolicy) *DegradeHandler {
+	if policy.Failures < 1 {
+		policy.Failures = 1
+	}
+	if policy.Recover <= 0 {
+		policy.Recover = time.Minute
+	}
+	if policy.Notify == nil {
+		policy.Notify = slog.NewTextHandler(os.Stderr, nil)
+	}
+	var spool slog.Handler
+	if policy.Spool != nil {
+		spool = replay.NewHandler(policy.Spool)
+	}
+	return &DegradeHandler{Handler: h, spool: spool, core: &degradeCore{policy: policy, changed: time.Now()}}
+}
+
+// Step returns the current rung, e.g. for a gauge. It only reads it: the
+// handler steps back up when it handles the next record.
+func (h *DegradeHandler) Step() DegradeStep {
+	h.core.mu.Lock()
+	defer h.core.mu.Unlock()
+	return h.core.step
+}
+
+// OnChange calls fn on every transition.
+func (h *DegradeHandler) OnChange(fn func(from, to DegradeStep)) {
+	h.core.mu.Lock()
+	defer h.core.mu.Unlock()
+
+	h.core.onChange = fn
+}
+
+func (h *DegradeHandler) Handle(ctx context.Context, r slog.Record) error {
+	c := h.core
+	now := time.Now()
+
+	switch step := c.current(ctx, now); {
+	case step >= StepErrorsOnly && r.Level < slog.LevelError,
+		step >= StepDropInfo && r.Level < slog.LevelWarn,
+		step >= StepDropDebug && r.Level < slog.LevelInfo:
+		return nil
+	case step == StepSpool:
+		return h.spool.Handle(ctx, r)
+	}
+
+	err := h.Handler.Handle(ctx, r)
+	c.observe(ctx, err != nil || (c.policy.Slow > 0 && time.Since(now) > c.policy.Slow))
+	return err
+}
+
+func (h *DegradeHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	spool := h.spool
+	if spool != nil {
+		spool = spool.WithAttrs(attrs)
+	}
+	return &DegradeHandler{Handler: h.Handler.WithAttrs(attrs), spool: spool, core: h.core}
+}
+
+func (h *DegradeHandler) WithGroup(name string) slog.Handler {
+	spool := h.spool
+	if spool != nil {
+		spool = spool.WithGroup(name)
+	}
+	return &DegradeHandler{Handler: h.Handler.WithGroup(name), spool: spool, core: h.core}
+}
+
+func (c *degradeCore) next(s DegradeStep, dir int) DegradeStep {
+	s += DegradeStep(dir)
+	if s == StepSpool && c.policy.Spool == nil {
+		s += DegradeStep(dir)
+	}
+	return min(max(s, StepNormal), StepErrorsOnly)
+}
+
+// current returns the rung, first stepping back up if the Recover period
+// passed since the last transition.
+func (c *degradeCore) current(ctx context.Context, now time.Time) DegradeStep {
+	c.mu.Lock()
+	from := c.step
+	if from == StepNormal || now.Sub(c.changed) < c.policy.Recover {
+		c.mu.Unlock()
+		return from
+	}
+	to := c.next(from, -1)
+	c.step, c.failures, c.changed = to, 0, now
+	onChange := c.onChange
+	c.mu.Unlock()
+
+	c.notify(ctx, onChange, slog.LevelInfo, "log pipeline recovered", from, to)
+	return to
+}
+
+// observe counts a failed or successful sink call and steps down the
+// ladder after policy.Failures consecutive failures.
+func (c *degradeCore) observe(ctx context.Context, failed bool) {
+	c.mu.Lock()
+	if !failed {
+		c.failures = 0
+		c.mu.Unlock()
+		return
+	}
+	c.failures++
+	from := c.step
+	if c.failures < c.policy.Failures || from == StepErrorsOnly {
+		c.mu.Unlock()
+		return
+	}
+	to := c.next(from, 1)
+	c.step, c.failures, c.changed = to, 0, time.Now()
+	onChange := c.onChange
+	c.mu.Unlock()
+
+	c.notify(ctx, onChange, slog.LevelWarn, "log pipeline degraded", from, to)
+}
+
+func (c *degradeCore) notify(ctx context.Context, onChange func(from, to DegradeStep), level slog.Level, msg string, from, to DegradeStep) {
+	if onChange != nil {
+		onChange(from, to)
+	}
+	if !c.policy.Notify.Enabled(ctx, level) {
+		return
+	}
+	r := slog.NewRecord(time.Now(), level, msg, 0)
+	r.AddAttrs(slog.String("from", from.String()), slog.String("to", to.String()))
+	_ = c.policy.Notify.Handle(ctx, r)
+}
@@ -0,0 +1,62 @@
+package logger
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+)
+
+// LevelsEnv is the conventional environment variable for level directives:
+//
+//	LOG_LEVELS=payments.db=debug,http=warn,*=info
+//	logger.ApplyLevelDirectives(os.Getenv(logger.LevelsEnv))
+const LevelsEnv string = "LOG_LEVELS"
+
+// ParseLevelDirectives parses comma-separated name=level directives, as
+// used by RUST_LOG. The name "*", or a level without a name, is the level
+// set by SetLevel.
+func ParseLevelDirectives(spec string) (map[string]string, error) {
+	out := make(map[string]string)
+	for _, d := range strings.Split(spec, ",") {
+		d = strings.TrimSpace(d)
+		if d == "" {
+			continue
+		}
+		name, level, ok := strings.Cut(d, "=")
+		if !ok {
+			name, level = "*", name
+		}
+		name, level = strings.TrimSpace(name), levelString(strings.TrimSpace(level))
+		if name == "" || level == "" {
+			return nil, fmt.Errorf("logger: level directive %q: want name=level", d)
+		}
+		out[name] = level
+	}
+	return out, nil
+}
+
+// ApplyLevelDirectives sets the level and replaces the named level
+// overrides with the directives of spec, or changes nothing if spec does
+// not parse.
+func ApplyLevelDirectives(spec string) error {
+	directives, err := ParseLevelDirectives(spec)
+	if err != nil {
+		return err
+	}
+
+	levels := make(map[string]slog.Level, len(directives))
+	for name, level := range directives {
+		if name == "*" {
+			if err := SetLevel(level); err != nil {
+				return err
+			}
+			continue
+		}
+		levels[name] = levelOf(level)
+	}
+
+	namedRegistry.mu.Lock()
+	namedRegistry.levels.Store(&levels)
+	namedRegistry.mu.Unlock()
+	return nil
+}
@@ -0,0 +1,177 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+)
+
+var errPoolClosed = errors.New("logger: encoding pool closed")
+
+// EncodePoolHandler moves encoding off the caller's goroutine: records are
+// queued to a bounded pool of workers, each encoding with its own handler
+// into a private buffer, and a single writer writes the results to w in the
+// order the records were handled. Handle blocks only when the queue is full.
+// Write errors are reported by Close.
+type EncodePoolHandler struct {
+	pool     *encodePool
+	handlers []slog.Handler
+}
+
+type encodePool struct {
+	w    io.Writer
+	bufs []*bytes.Buffer
+
+	seq     atomic.Uint64
+	mu      sync.RWMutex
+	closed  bool
+	jobs    chan encodeJob
+	results chan encodeResult
+	workers sync.WaitGroup
+	done    chan struct{}
+	err     error
+}
+
+type encodeJob struct {
+	seq      uint64
+	ctx      context.Context
+	r        slog.Record
+	handlers []slog.Handler
+}
+
+type encodeResult struct {
+	seq uint64
+	b   []byte
+	err error
+}
+
+// NewEncodePoolHandler encodes with workers handlers built by newHandler,
+// queueing up to queue records:
+//
+//	h := logger.NewEncodePoolHandler(conn, 4, 1024, func(w io.Writer) slog.Handler {
+//		return slog.NewJSONHandler(w, nil)
+//	})
+//	defer h.Close()
+func NewEncodePoolHandler(w io.Writer, workers, queue int, newHandler func(io.Writer) slog.Handler) *EncodePoolHandler {
+	workers = max(workers, 1)
+	p := &encodePool{
+		w:       w,
+		jobs:    make(chan encodeJob, max(queue, 1)),
+		results: make(chan encodeResult, max(queue, 1)),
+		done:    make(chan struct{}),
+	}
+	handlers := make([]slog.Handler, workers)
+	for i := range handlers {
+		buf := &bytes.Buffer{}
+		p.bufs = append(p.bufs, buf)
+		handlers[i] = newHandler(buf)
+	}
+
+	p.workers.Add(workers)
+	for i := 0; i < workers; i++ {
+		go p.work(i)
+	}
+	go p.write()
+	return &EncodePoolHandler{pool: p, handlers: handlers}
+}
+
+func (p *encodePool) work(i int) {
+	defer p.workers.Done()
+
+	buf := p.bufs[i]
+	for job := range p.jobs {
+		buf.Reset()
+		err := job.handlers[i].Handle(job.ctx, job.r)
+		p.results <- encodeResult{seq: job.seq, b: bytes.Clone(buf.Bytes()), err: err}
+	}
+}
+
+// write writes the encoded records in sequence order, holding back those
+// that finished ahead of an earlier one.
+func (p *encodePool) write() {
+	defer close(p.done)
+
+	next := uint64(1)
+	pending := make(map[uint64]encodeResult)
+	for res := range p.results {
+		pending[res.seq] = res
+		for {
+			res, ok := pending[next]
+			if !ok {
+				break
+			}
+			delete(pending, next)
+			next++
+
+			err := res.err
+			if err == nil && len(res.b) > 0 {
+				_, err = p.w.Write(res.b)
+			}
+			if err != nil && p.err == nil {
+				p.err = err
+			}
+		}
+	}
+}
+
+// QueueDepth returns the number of records waiting to be encoded or
+// written.
+func (h *EncodePoolHandler) QueueDepth() int {
+	return len(h.pool.jobs) + len(h.pool.results)
+}
+
+func (h *EncodePoolHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.handlers[0].Enabled(ctx, level)
+}
+
+func (h *EncodePoolHandler) Handle(ctx context.Context, r slog.Record) error {
+	p := h.pool
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if p.closed {
+		return errPoolClosed
+	}
+	p.jobs <- encodeJob{seq: p.seq.Add(1), ctx: context.WithoutCancel(ctx), r: r.Clone(), handlers: h.handlers}
+	return nil
+}
+
+func (h *EncodePoolHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	handlers := make([]slog.Handler, len(h.handlers))
+	for i, hh := range h.handlers {
+		handlers[i] = hh.WithAttrs(attrs)
+	}
+	return &EncodePoolHandler{pool: h.pool, handlers: handlers}
+}
+
+func (h *EncodePoolHandler) WithGroup(name string) slog.Handler {
+	handlers := make([]slog.Handler, len(h.handlers))
+	for i, hh := range h.handlers {
+		handlers[i] = hh.WithGroup(name)
+	}
+	return &EncodePoolHandler{pool: h.pool, handlers: handlers}
+}
+
+// Close writes the queued records, stops the workers and returns the first
+// encoding or write error.
+func (h *EncodePoolHandler) Close() error {
+	p := h.pool
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		<-p.done
+		return p.err
+	}
+	p.closed = true
+	close(p.jobs)
+	p.mu.Unlock()
+
+	p.workers.Wait()
+	close(p.results)
+	<-p.done
+	return p.err
+}
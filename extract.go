@@ -0,0 +1,108 @@
+package logger
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+)
+
+// Extractor returns attrs taken from ctx, added to every record logged with
+// it by ContextHandler.
+type Extractor func(ctx context.Context) []slog.Attr
+
+type namedExtractor struct {
+	name string
+	fn   Extractor
+}
+
+// extractorSet is shared by a ContextHandler and the handlers derived from
+// it. Handle reads the current list without locking; changes copy it and
+// swap it in, so a record sees either the old or the new list.
+type extractorSet struct {
+	mu   sync.Mutex
+	list atomic.Pointer[[]namedExtractor]
+}
+
+// WithExtractor registers fn under name with the ContextHandler built by
+// NewLogger. A later extractor with the same name replaces it.
+//
+//	logger.NewLogger(os.Stdout, logger.WithExtractor("tenant", func(ctx context.Context) []slog.Attr {
+//		return []slog.Attr{slog.String("tenant", tenant.FromContext(ctx))}
+//	}))
+func WithExtractor(name string, fn Extractor) Option {
+	return func(opts *loggerOptions) {
+		opts.extractors = append(opts.extractors, namedExtractor{name: name, fn: fn})
+	}
+}
+
+func newExtractorSet(list []namedExtractor) *extractorSet {
+	s := &extractorSet{}
+	for _, e := range list {
+		s.set(e.name, e.fn)
+	}
+	return s
+}
+
+func (s *extractorSet) set(name string, fn Extractor) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var list []namedExtractor
+	if cur := s.list.Load(); cur != nil {
+		list = make([]namedExtractor, 0, len(*cur)+1)
+		for _, e := range *cur {
+			if e.name != name {
+				list = append(list, e)
+			}
+		}
+	}
+	if fn != nil {
+		list = append(list, namedExtractor{name: name, fn: fn})
+	}
+	s.list.Store(&list)
+}
+
+func (s *extractorSet) extract(ctx context.Context) (as []slog.Attr) {
+	if s == nil {
+		return nil
+	}
+	list := s.list.Load()
+	if list == nil {
+		return nil
+	}
+	for _, e := range *list {
+		as = append(as, e.fn(ctx)...)
+	}
+	return as
+}
+
+// AddExtractor registers fn under name, replacing an extractor of the same
+// name, for h and every handler derived from it. It is safe to call while
+// records are being logged.
+func (h ContextHandler) AddExtractor(name string, fn Extractor) {
+	if h.extractors != nil {
+		h.extractors.set(name, fn)
+	}
+}
+
+// RemoveExtractor unregisters the extractor named name.
+func (h ContextHandler) RemoveExtractor(name string) {
+	if h.extractors != nil {
+		h.extractors.set(name, nil)
+	}
+}
+
+// AddExtractor registers fn with the default logger, if NewLogger built it.
+func AddExtractor(name string, fn Extractor) {
+	if h, ok := slog.Default().Handler().(ContextHandler); ok {
+		h.AddExtractor(name, fn)
+	}
+}
+
+// RemoveExtractor unregisters name from the default logger.
+func RemoveExtractor(name string) {
+	if h, ok := slog.Default().Handler().(ContextHandler); ok {
+		h.RemoveExtractor(name)
+	}
+}
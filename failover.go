@@ -0,0 +1,162 @@
+package logger
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// Sinks reported by FailoverHandler.Active.
+const (
+	FailoverPrimary   string = "primary"
+	FailoverSecondary string = "secondary"
+)
+
+type FailoverOption func(*failoverOptions)
+
+type failoverOptions struct {
+	threshold int
+	probe     time.Duration
+}
+
+// WithFailoverThreshold fails over after n consecutive primary errors.
+// Default: 1.
+func WithFailoverThreshold(n int) FailoverOption {
+	return func(opts *failoverOptions) {
+		opts.threshold = n
+	}
+}
+
+// WithFailoverProbe sets how long records go to the secondary before the
+// primary is tried again. Default: 30s.
+func WithFailoverProbe(d time.Duration) FailoverOption {
+	return func(opts *failoverOptions) {
+		opts.probe = d
+	}
+}
+
+func FailoverOptions(options ...FailoverOption) *failoverOptions {
+	opts := &failoverOptions{threshold: 1, probe: 30 * time.Second}
+	for _, opt := range options {
+		opt(opts)
+	}
+	opts.threshold = max(opts.threshold, 1)
+	return opts
+}
+
+// FailoverHandler writes to a primary handler and, once it failed
+// threshold times in a row, to a secondary one, e.g. a local file for a
+// network sink. Records the primary fails on are written to the secondary.
+// Every probe interval one record is tried on the primary again, failing
+// back if it succeeds.
+type FailoverHandler struct {
+	primary   slog.Handler
+	secondary slog.Handler
+	state     *failoverState
+}
+
+type failoverState struct {
+	opts *failoverOptions
+
+	mu       sync.Mutex
+	failures int
+	failed   bool
+	retry    time.Time
+}
+
+// logger.NewFailoverHandler(remote, local, logger.WithFailoverProbe(time.Minute))
+func NewFailoverHandler(primary, secondary slog.Handler, options ...FailoverOption) *FailoverHandler {
+	return &FailoverHandler{
+		primary:   primary,
+		secondary: secondary,
+		state:     &failoverState{opts: FailoverOptions(options...)},
+	}
+}
+
+// Active returns FailoverPrimary or FailoverSecondary.
+func (h *FailoverHandler) Active() string {
+	h.state.mu.Lock()
+	defer h.state.mu.Unlock()
+
+	if h.state.failed {
+		return FailoverSecondary
+	}
+	return FailoverPrimary
+}
+
+// usePrimary reports whether a record goes to the primary: when it is
+// active, or when it is time to probe it.
+func (s *failoverState) usePrimary(now time.Time) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.failed {
+		return true
+	}
+	if now.Before(s.retry) {
+		return false
+	}
+	s.retry = now.Add(s.opts.probe)
+	return true
+}
+
+// done records the result of a primary write.
+func (s *failoverState) done(err error, now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err == nil {
+		s.failures, s.failed = 0, false
+		return
+	}
+	s.failures++
+	if s.failed || s.failures >= s.opts.threshold {
+		s.failed = true
+		s.retry = now.Add(s.opts.probe)
+	}
+}
+
+func (h *FailoverHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.primary.Enabled(ctx, level) || h.secondary.Enabled(ctx, level)
+}
+
+func (h *FailoverHandler) Handle(ctx context.Context, r slog.Record) error {
+	if !h.primary.Enabled(ctx, r.Level) {
+		// nothing to learn about the primary: keep its failure count
+		if h.Active() == FailoverSecondary {
+			return h.handle(ctx, h.secondary, r)
+		}
+		return nil
+	}
+
+	now := time.Now()
+	if !h.state.usePrimary(now) {
+		return h.handle(ctx, h.secondary, r)
+	}
+	err := h.primary.Handle(ctx, r.Clone())
+	h.state.done(err, now)
+	if err == nil {
+		return nil
+	}
+	if serr := h.handle(ctx, h.secondary, r); serr != nil {
+		return errors.Join(err, serr)
+	}
+	return nil
+}
+
+func (h *FailoverHandler) handle(ctx context.Context, sh slog.Handler, r slog.Record) error {
+	if !sh.Enabled(ctx, r.Level) {
+		return nil
+	}
+	return sh.Handle(ctx, r)
+}
+
+func (h *FailoverHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &FailoverHandler{primary: h.primary.WithAttrs(attrs), secondary: h.secondary.WithAttrs(attrs), state: h.state}
+}
+
+func (h *FailoverHandler) WithGroup(name string) slog.Handler {
+	return &FailoverHandler{primary: h.primary.WithGroup(name), secondary: h.secondary.WithGroup(name), state: h.state}
+}
@@ -0,0 +1,292 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// FastTextHandler writes logfmt text with as little overhead as
+// possible: no ReplaceAttr, and members of groups, from group attrs and
+// WithGroup alike, are written as group.key=value pairs. Attrs from
+// WithAttrs are encoded once.
+type FastTextHandler struct {
+	w          io.Writer
+	mu         *sync.Mutex
+	level      slog.Leveler
+	timeFormat string
+	location   *time.Location
+	keys       KeyNames
+	epoch      string
+	styles     []levelStyle
+	multiline  bool
+	prefix     []byte
+	group      string
+}
+
+var fastTextPool = newBufferPool()
+
+// logger.NewLogger(os.Stdout, logger.WithFastText(true))
+func NewFastTextHandler(w io.Writer, level slog.Leveler, timeFormat string) *FastTextHandler {
+	if level == nil {
+		level = slog.LevelInfo
+	}
+	if timeFormat == "" {
+		timeFormat = time.RFC3339
+	}
+	return &FastTextHandler{w: w, mu: &sync.Mutex{}, level: level, timeFormat: timeFormat, keys: KeyNames{}.withDefaults()}
+}
+
+func (h *FastTextHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= h.level.Level()
+}
+
+func (h *FastTextHandler) Leveler() slog.Leveler {
+	return h.level
+}
+
+func (h *FastTextHandler) Handle(_ context.Context, r slog.Record) error {
+	bp := fastTextPool.get()
+	buf := (*bp)[:0]
+
+	style := levelStyleFor(h.styles, r.Level)
+	if style.Icon != "" {
+		buf = append(buf, style.Icon...)
+		buf = append(buf, ' ')
+	}
+	if !r.Time.IsZero() {
+		buf = appendFastKey(buf, h.keys.Time)
+		buf = append(buf, '=')
+		if h.epoch != "" {
+			buf = appendEpoch(buf, r.Time, h.epoch)
+		} else {
+			t := r.Time
+			if h.location != nil {
+				t = t.In(h.location)
+			}
+			buf = t.AppendFormat(buf, h.timeFormat)
+		}
+		buf = append(buf, ' ')
+	}
+	buf = appendFastKey(buf, h.keys.Level)
+	buf = append(buf, '=')
+	buf = appendLevel(buf, style, r.Level)
+	if r.Message != "" {
+		buf = append(buf, ' ')
+		buf = appendFastKey(buf, h.keys.Message)
+		buf = append(buf, '=')
+		buf = appendFastString(buf, r.Message)
+	}
+	buf = append(buf, h.prefix...)
+	var trailer []string
+	r.Attrs(func(a slog.Attr) bool {
+		if a.Value.Kind() != slog.KindAny {
+			buf = appendFastAttr(buf, h.group, a)
+			return true
+		}
+		if a.Key == slog.SourceKey {
+			if s, ok := a.Value.Any().(*slog.Source); ok && s != nil {
+				buf = h.appendSource(buf, s)
+				return true
+			}
+		}
+		if h.multiline {
+			if err, ok := a.Value.Any().(error); ok {
+				if first, rest, ok := strings.Cut(err.Error(), "\n"); ok {
+					buf = appendFastAttr(buf, h.group, slog.String(a.Key, first))
+					trailer = append(trailer, rest)
+					return true
+				}
+			}
+		}
+		buf = appendFastAttr(buf, h.group, a)
+		return true
+	})
+	buf = append(buf, '\n')
+	for _, t := range trailer {
+		for _, line := range strings.Split(t, "\n") {
+			buf = append(buf, '\t')
+			buf = append(buf, line...)
+			buf = append(buf, '\n')
+		}
+	}
+
+	h.mu.Lock()
+	_, err := h.w.Write(buf)
+	h.mu.Unlock()
+
+	*bp = buf
+	fastTextPool.put(bp)
+	return err
+}
+
+func (h *FastTextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	prefix := make([]byte, len(h.prefix), len(h.prefix)+64)
+	copy(prefix, h.prefix)
+	for _, a := range attrs {
+		prefix = appendFastAttr(prefix, h.group, a)
+	}
+	h2 := *h
+	h2.prefix = prefix
+	return &h2
+}
+
+func (h *FastTextHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	h2 := *h
+	h2.group = h.group + name + "."
+	return &h2
+}
+
+// appendSource appends the caller of the record at the top level, whatever
+// the group of the handler.
+func (h *FastTextHandler) appendSource(buf []byte, s *slog.Source) []byte {
+	buf = append(buf, ' ')
+	buf = appendFastKey(buf, h.keys.Source)
+	buf = append(buf, '=')
+	buf = append(buf, filepath.Base(filepath.Dir(s.File))...)
+	buf = append(buf, '/')
+	buf = append(buf, filepath.Base(s.File)...)
+	buf = append(buf, ':')
+	return strconv.AppendInt(buf, int64(s.Line), 10)
+}
+
+// appendFastAttr appends a with its key qualified by group, the dotted path
+// of the enclosing groups. Group members are appended recursively.
+func appendFastAttr(buf []byte, group string, a slog.Attr) []byte {
+	v := a.Value.Resolve()
+	if v.Kind() == slog.KindGroup {
+		if a.Key != "" {
+			group += a.Key + "."
+		}
+		for _, ga := range v.Group() {
+			buf = appendFastAttr(buf, group, ga)
+		}
+		return buf
+	}
+	if a.Key == "" {
+		return buf
+	}
+
+	buf = append(buf, ' ')
+	if group != "" {
+		buf = appendFastKey(buf, group+a.Key)
+	} else {
+		buf = appendFastKey(buf, a.Key)
+	}
+	buf = append(buf, '=')
+
+	switch v.Kind() {
+	case slog.KindString:
+		return appendFastString(buf, v.String())
+	case slog.KindInt64:
+		return strconv.AppendInt(buf, v.Int64(), 10)
+	case slog.KindUint64:
+		return strconv.AppendUint(buf, v.Uint64(), 10)
+	case slog.KindFloat64:
+		return strconv.AppendFloat(buf, v.Float64(), 'g', -1, 64)
+	case slog.KindBool:
+		return strconv.AppendBool(buf, v.Bool())
+	case slog.KindDuration:
+		return append(buf, v.Duration().String()...)
+	case slog.KindTime:
+		return v.Time().AppendFormat(buf, time.RFC3339)
+	default:
+		if err, ok := v.Any().(error); ok {
+			return appendFastString(buf, err.Error())
+		}
+		return appendFastString(buf, fmt.Sprint(v.Any()))
+	}
+}
+
+// appendFastString appends s as a logfmt value, quoted and escaped the way
+// go-logfmt does when it contains spaces, '=', '"', control characters or
+// invalid UTF-8.
+func appendFastString(buf []byte, s string) []byte {
+	if s == "" || needsQuote(s) {
+		return appendLogfmtQuoted(buf, s)
+	}
+	return append(buf, s...)
+}
+
+// appendFastKey appends s as a logfmt key. Characters logfmt does not allow
+// in keys are replaced with '_', as records cannot be rejected half-written.
+func appendFastKey(buf []byte, s string) []byte {
+	if !needsQuote(s) {
+		return append(buf, s...)
+	}
+	for _, r := range s {
+		if invalidLogfmtRune(r) {
+			r = '_'
+		}
+		buf = utf8.AppendRune(buf, r)
+	}
+	return buf
+}
+
+func needsQuote(s string) bool {
+	for i := 0; i < len(s); {
+		if c := s[i]; c < utf8.RuneSelf {
+			if invalidLogfmtRune(rune(c)) {
+				return true
+			}
+			i++
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if r == utf8.RuneError {
+			return true
+		}
+		i += size
+	}
+	return false
+}
+
+func invalidLogfmtRune(r rune) bool {
+	return r <= ' ' || r == '=' || r == '"' || r == 0x7f || r == utf8.RuneError
+}
+
+const hexDigits = "0123456789abcdef"
+
+func appendLogfmtQuoted(buf []byte, s string) []byte {
+	buf = append(buf, '"')
+	for i := 0; i < len(s); {
+		c := s[i]
+		if c < utf8.RuneSelf {
+			switch {
+			case c == '"' || c == '\\':
+				buf = append(buf, '\\', c)
+			case c == '\n':
+				buf = append(buf, `\n`...)
+			case c == '\r':
+				buf = append(buf, `\r`...)
+			case c == '\t':
+				buf = append(buf, `\t`...)
+			case c < ' ' || c == 0x7f:
+				buf = append(buf, `\u00`...)
+				buf = append(buf, hexDigits[c>>4], hexDigits[c&0xf])
+			default:
+				buf = append(buf, c)
+			}
+			i++
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if r == utf8.RuneError && size == 1 {
+			buf = append(buf, `\ufffd`...)
+		} else {
+			buf = append(buf, s[i:i+size]...)
+		}
+		i += size
+	}
+	return append(buf, '"')
+}
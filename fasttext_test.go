@@ -0,0 +1,92 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log/slog"
+	"strconv"
+	"strings"
+	"testing"
+	"testing/slogtest"
+	"time"
+)
+
+func TestFastTextHandlerConformance(t *testing.T) {
+	var buf bytes.Buffer
+	h := NewFastTextHandler(&buf, nil, "")
+
+	results := func() []map[string]any {
+		var ms []map[string]any
+		for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+			m, err := parseLogfmt(line)
+			if err != nil {
+				t.Fatalf("%s: %v", line, err)
+			}
+			ms = append(ms, m)
+		}
+		return ms
+	}
+	if err := slogtest.TestHandler(h, results); err != nil {
+		t.Error(err)
+	}
+}
+
+// parseLogfmt parses a FastTextHandler line, nesting the dotted keys of
+// group members.
+func parseLogfmt(line string) (map[string]any, error) {
+	m := map[string]any{}
+	for line != "" {
+		var kv string
+		key, rest, _ := strings.Cut(line, "=")
+		if strings.HasPrefix(rest, `"`) {
+			prefix, err := strconv.QuotedPrefix(rest)
+			if err != nil {
+				return nil, err
+			}
+			if kv, err = strconv.Unquote(prefix); err != nil {
+				return nil, err
+			}
+			rest = strings.TrimPrefix(rest[len(prefix):], " ")
+		} else {
+			kv, rest, _ = strings.Cut(rest, " ")
+		}
+		line = rest
+
+		group := m
+		path := strings.Split(key, ".")
+		for _, g := range path[:len(path)-1] {
+			sub, ok := group[g].(map[string]any)
+			if !ok {
+				sub = map[string]any{}
+				group[g] = sub
+			}
+			group = sub
+		}
+		group[path[len(path)-1]] = kv
+	}
+	return m, nil
+}
+
+// BenchmarkFastTextHandler should report 0 allocs/op: attrs are encoded
+// into a pooled buffer without boxing their values.
+func BenchmarkFastTextHandler(b *testing.B) {
+	h := NewFastTextHandler(io.Discard, nil, "").WithAttrs([]slog.Attr{slog.String("service", "orders")})
+	ctx := context.Background()
+	r := slog.NewRecord(time.Now(), slog.LevelInfo, "order placed", 0)
+	r.AddAttrs(
+		slog.String("id", "a1b2c3"),
+		slog.Int("items", 3),
+		slog.Float64("total", 42.5),
+		slog.Bool("paid", true),
+		slog.Duration("took", 1500*time.Microsecond),
+	)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := h.Handle(ctx, r); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,131 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"runtime"
+	"sync"
+	"time"
+)
+
+const (
+	LevelFatal string = "FATAL"
+	LevelPanic string = "PANIC"
+)
+
+// Levels of the records logged by Fatal and Panic, above slog.LevelError.
+const (
+	FatalLevel slog.Level = slog.LevelError + 4
+	PanicLevel slog.Level = slog.LevelError + 8
+)
+
+// levelName names level like slog.Level.String, and FatalLevel and
+// PanicLevel as FATAL and PANIC.
+func levelName(level slog.Level) string {
+	switch level {
+	case FatalLevel:
+		return LevelFatal
+	case PanicLevel:
+		return LevelPanic
+	}
+	return level.String()
+}
+
+var exitHooks struct {
+	mu    sync.Mutex
+	hooks []func()
+}
+
+// exitTimeout bounds how long Fatal waits for the registered closers to
+// flush and close.
+const exitTimeout = 5 * time.Second
+
+// osExit is replaced in tests.
+var osExit = os.Exit
+
+// RegisterExitHook registers fn to run before Fatal exits or Panic panics,
+// e.g. to close an EncodePoolHandler or flush a transport, so the record
+// explaining the exit is not lost. Hooks run once, the last registered
+// first.
+//
+//	logger.RegisterExitHook(func() { _ = pool.Close() })
+func RegisterExitHook(fn func()) {
+	exitHooks.mu.Lock()
+	defer exitHooks.mu.Unlock()
+	exitHooks.hooks = append(exitHooks.hooks, fn)
+}
+
+// RunExitHooks runs and unregisters the exit hooks, for programs exiting on
+// their own.
+func RunExitHooks() {
+	exitHooks.mu.Lock()
+	hooks := exitHooks.hooks
+	exitHooks.hooks = nil
+	exitHooks.mu.Unlock()
+
+	for i := len(hooks) - 1; i >= 0; i-- {
+		func() {
+			defer func() { _ = recover() }()
+			hooks[i]()
+		}()
+	}
+}
+
+// Fatal logs msg at FatalLevel with the default logger, runs Shutdown,
+// waiting up to 5s for the registered closers to flush and close, and exits
+// with status 1.
+func Fatal(msg string, args ...any) {
+	logExit(context.Background(), FatalLevel, msg, args...)
+	ctx, cancel := context.WithTimeout(context.Background(), exitTimeout)
+	defer cancel()
+	if err := Shutdown(ctx); err != nil {
+		fmt.Fprintf(os.Stderr, "logger: shutdown: %v\n", err)
+	}
+	osExit(1)
+}
+
+func FatalContext(ctx context.Context, msg string, args ...any) {
+	logExit(ctx, FatalLevel, msg, args...)
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), exitTimeout)
+	defer cancel()
+	if err := Shutdown(ctx); err != nil {
+		fmt.Fprintf(os.Stderr, "logger: shutdown: %v\n", err)
+	}
+	osExit(1)
+}
+
+// Panic logs msg at PanicLevel with the default logger, runs the exit hooks,
+// flushes the registered closers and panics with msg. The closers stay open
+// for programs recovering from the panic.
+func Panic(msg string, args ...any) {
+	logExit(context.Background(), PanicLevel, msg, args...)
+	flushExit()
+	panic(msg)
+}
+
+func PanicContext(ctx context.Context, msg string, args ...any) {
+	logExit(ctx, PanicLevel, msg, args...)
+	flushExit()
+	panic(msg)
+}
+
+// logExit logs a record for the caller of Fatal or Panic, even if the
+// default logger is not enabled at level.
+func logExit(ctx context.Context, level slog.Level, msg string, args ...any) {
+	var pcs [1]uintptr
+	runtime.Callers(3, pcs[:])
+	r := slog.NewRecord(time.Now(), level, msg, pcs[0])
+	r.Add(args...)
+	if err := slog.Default().Handler().Handle(ctx, r); err != nil {
+		fmt.Fprintf(os.Stderr, "logger: %s: %v\n", msg, err)
+	}
+}
+
+func flushExit() {
+	RunExitHooks()
+	if err := Flush(); err != nil {
+		fmt.Fprintf(os.Stderr, "logger: flush: %v\n", err)
+	}
+}
@@ -0,0 +1,72 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+type exitCloser struct {
+	flushed, closed bool
+}
+
+func (c *exitCloser) Flush() error {
+	c.flushed = true
+	return nil
+}
+
+func (c *exitCloser) Close() error {
+	c.closed = true
+	return nil
+}
+
+func TestFatalFlushesBeforeExit(t *testing.T) {
+	defer slog.SetDefault(slog.Default())
+	defer func(exit func(int)) { osExit = exit }(osExit)
+
+	code := -1
+	osExit = func(c int) { code = c }
+
+	var buf bytes.Buffer
+	NewLogger(&buf)
+	c := &exitCloser{}
+	RegisterCloser(c)
+	hooked := false
+	RegisterExitHook(func() { hooked = true })
+
+	Fatal("giving up", "reason", "test")
+
+	if code != 1 {
+		t.Errorf("exit code = %d, want 1", code)
+	}
+	if !strings.Contains(buf.String(), "level=FATAL") || !strings.Contains(buf.String(), "giving up") {
+		t.Errorf("record not logged: %q", buf.String())
+	}
+	if !hooked || !c.flushed || !c.closed {
+		t.Errorf("hook run %v, flushed %v, closed %v; want all", hooked, c.flushed, c.closed)
+	}
+}
+
+func TestPanicFlushesWithoutClosing(t *testing.T) {
+	defer slog.SetDefault(slog.Default())
+
+	NewLogger(&bytes.Buffer{})
+	c := &exitCloser{}
+	RegisterCloser(c)
+	defer Shutdown(context.Background())
+
+	func() {
+		defer func() {
+			if r := recover(); r != "boom" {
+				t.Errorf("recovered %v, want boom", r)
+			}
+		}()
+		Panic("boom")
+	}()
+
+	if !c.flushed || c.closed {
+		t.Errorf("flushed %v, closed %v; want flushed only", c.flushed, c.closed)
+	}
+}
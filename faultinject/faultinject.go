@@ -0,0 +1,191 @@
+// Package faultinject wraps handlers and writers to inject latency, errors
+// and partial writes, so degradation policies, failover and retries can be
+// exercised in tests or staging before a real outage does it.
+package faultinject
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ErrInjected is the error injected unless WithError sets another one.
+var ErrInjected = errors.New("faultinject: injected failure")
+
+type Option func(*faultOptions)
+
+type faultOptions struct {
+	latency     time.Duration
+	jitter      time.Duration
+	errorRate   float64
+	err         error
+	partialRate float64
+	seed        int64
+}
+
+// WithLatency delays every record or write by d plus a random duration of
+// up to jitter.
+func WithLatency(d, jitter time.Duration) Option {
+	return func(opts *faultOptions) {
+		opts.latency = d
+		opts.jitter = jitter
+	}
+}
+
+// WithErrorRate fails the given fraction, from 0 to 1, of records or writes
+// without passing them on.
+func WithErrorRate(rate float64) Option {
+	return func(opts *faultOptions) {
+		opts.errorRate = rate
+	}
+}
+
+// WithError sets the injected error.
+func WithError(err error) Option {
+	return func(opts *faultOptions) {
+		opts.err = err
+	}
+}
+
+// WithPartialWriteRate makes a Writer write only a prefix of the given
+// fraction of writes and return io.ErrShortWrite. It does not apply to
+// Handler.
+func WithPartialWriteRate(rate float64) Option {
+	return func(opts *faultOptions) {
+		opts.partialRate = rate
+	}
+}
+
+// WithSeed makes the injected faults reproducible.
+func WithSeed(seed int64) Option {
+	return func(opts *faultOptions) {
+		opts.seed = seed
+	}
+}
+
+func FaultOptions(options ...Option) *faultOptions {
+	opts := &faultOptions{
+		err:  ErrInjected,
+		seed: time.Now().UnixNano(),
+	}
+	for _, opt := range options {
+		opt(opts)
+	}
+	return opts
+}
+
+// injector is shared by a Handler and the handlers derived from it.
+type injector struct {
+	opts    *faultOptions
+	enabled atomic.Bool
+	mu      sync.Mutex
+	rand    *rand.Rand
+}
+
+func newInjector(options []Option) *injector {
+	opts := FaultOptions(options...)
+	in := &injector{opts: opts, rand: rand.New(rand.NewSource(opts.seed))}
+	in.enabled.Store(true)
+	return in
+}
+
+func (in *injector) float() float64 {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	return in.rand.Float64()
+}
+
+// delay sleeps the configured latency, returning early if ctx is done.
+func (in *injector) delay(ctx context.Context) {
+	d := in.opts.latency
+	if in.opts.jitter > 0 {
+		d += time.Duration(in.float() * float64(in.opts.jitter))
+	}
+	if d <= 0 {
+		return
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+	case <-ctx.Done():
+	}
+}
+
+func (in *injector) fail() bool {
+	return in.opts.errorRate > 0 && in.float() < in.opts.errorRate
+}
+
+// Handler injects faults into the records passed to the wrapped handler.
+type Handler struct {
+	slog.Handler
+	in *injector
+}
+
+// h := faultinject.NewHandler(sink, faultinject.WithLatency(50*time.Millisecond, 0), faultinject.WithErrorRate(0.1))
+func NewHandler(h slog.Handler, options ...Option) *Handler {
+	return &Handler{Handler: h, in: newInjector(options)}
+}
+
+// SetEnabled turns fault injection on or off, for h and the handlers
+// derived from it. It is on initially.
+func (h *Handler) SetEnabled(enabled bool) {
+	h.in.enabled.Store(enabled)
+}
+
+func (h *Handler) Handle(ctx context.Context, r slog.Record) error {
+	if h.in.enabled.Load() {
+		h.in.delay(ctx)
+		if h.in.fail() {
+			return h.in.opts.err
+		}
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h *Handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &Handler{Handler: h.Handler.WithAttrs(attrs), in: h.in}
+}
+
+func (h *Handler) WithGroup(name string) slog.Handler {
+	return &Handler{Handler: h.Handler.WithGroup(name), in: h.in}
+}
+
+// Writer injects faults into the writes to the wrapped writer.
+type Writer struct {
+	w  io.Writer
+	in *injector
+}
+
+// w := faultinject.NewWriter(file, faultinject.WithPartialWriteRate(0.05))
+func NewWriter(w io.Writer, options ...Option) *Writer {
+	return &Writer{w: w, in: newInjector(options)}
+}
+
+// SetEnabled turns fault injection on or off. It is on initially.
+func (w *Writer) SetEnabled(enabled bool) {
+	w.in.enabled.Store(enabled)
+}
+
+func (w *Writer) Write(p []byte) (int, error) {
+	if !w.in.enabled.Load() {
+		return w.w.Write(p)
+	}
+	w.in.delay(context.Background())
+	if w.in.fail() {
+		return 0, w.in.opts.err
+	}
+	if w.in.opts.partialRate > 0 && len(p) > 1 && w.in.float() < w.in.opts.partialRate {
+		n, err := w.w.Write(p[:int(w.in.float()*float64(len(p)-1))+1])
+		if err == nil {
+			err = io.ErrShortWrite
+		}
+		return n, err
+	}
+	return w.w.Write(p)
+}
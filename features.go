@@ -0,0 +1,34 @@
+package logger
+
+import (
+	"errors"
+	"sort"
+)
+
+// ErrUnsupported is returned by the sinks left out of the build by a build
+// tag.
+var ErrUnsupported = errors.New("logger: not supported in this build")
+
+// Optional features register themselves from files built unless their tag
+// is set, so a binary only links the dependencies it needs:
+//
+//	logger_nogorm   the GORM adapter
+//	logger_nogokit  the go-kit adapter
+//	logger_nohttp   the HTTP middleware and control endpoint
+//	logger_nofile   file rotation and the file-backed CrashHandler
+//	logger_lite     all of the above
+//
+// Prometheus, OpenTelemetry and the transport live in their own packages and
+// are never linked unless imported.
+var features []string
+
+func registerFeature(name string) {
+	features = append(features, name)
+}
+
+// Features returns the names of the optional features built in, sorted.
+func Features() []string {
+	out := append([]string(nil), features...)
+	sort.Strings(out)
+	return out
+}
@@ -0,0 +1,84 @@
+// Package fields builds slog attributes for values that need careful encoding.
+package fields
+
+import (
+	"fmt"
+	"log/slog"
+	"reflect"
+)
+
+const PanicKey string = "panic"
+
+// Panic encodes a recovered panic value as a group with its dynamic type and
+// a string form. Formatting is guarded: Error and String methods that panic
+// themselves, or that are called on nil pointers, fall back to a %#v of the
+// raw value without calling methods.
+//
+//	defer func() {
+//		if v := recover(); v != nil {
+//			slog.Error("handler panicked", fields.Panic(v))
+//		}
+//	}()
+func Panic(v any) slog.Attr {
+	return slog.Group(PanicKey,
+		slog.String("type", typeName(v)),
+		slog.String("value", safeString(v)),
+	)
+}
+
+func typeName(v any) string {
+	if v == nil {
+		return "nil"
+	}
+	return reflect.TypeOf(v).String()
+}
+
+func safeString(v any) (s string) {
+	if v == nil {
+		return "<nil>"
+	}
+	if rv := reflect.ValueOf(v); isNil(rv) {
+		return "<nil " + rv.Type().String() + ">"
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			s = rawString(v)
+		}
+	}()
+	switch v := v.(type) {
+	case error:
+		return v.Error()
+	case fmt.Stringer:
+		return v.String()
+	case string:
+		return v
+	}
+	return rawString(v)
+}
+
+// rawString formats v without invoking its methods: %#v on a value whose
+// type has no methods never reaches user code.
+func rawString(v any) (s string) {
+	defer func() {
+		if r := recover(); r != nil {
+			s = fmt.Sprintf("<unprintable %T>", v)
+		}
+	}()
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Pointer {
+		rv = rv.Elem()
+	}
+	if rv.CanInterface() && rv.Type().NumMethod() == 0 {
+		return fmt.Sprintf("%#v", rv.Interface())
+	}
+	return fmt.Sprintf("<%T>", v)
+}
+
+func isNil(rv reflect.Value) bool {
+	switch rv.Kind() {
+	case reflect.Chan, reflect.Func, reflect.Interface, reflect.Map, reflect.Pointer, reflect.Slice:
+		return rv.IsNil()
+	}
+	return false
+}
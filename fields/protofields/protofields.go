@@ -0,0 +1,129 @@
+// Package protofields builds slog attributes for protobuf messages. It is
+// kept apart from package fields so that only programs logging messages link
+// the protobuf runtime.
+package protofields
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+const redacted = "[REDACTED]"
+
+type ProtoOption func(*protoOptions)
+
+type protoOptions struct {
+	redact   [][]string
+	maxBytes int
+}
+
+// WithProtoRedact replaces the fields at paths, field mask paths of proto
+// field names like "user.email", with "[REDACTED]". Paths through repeated
+// fields apply to every element.
+func WithProtoRedact(paths ...string) ProtoOption {
+	return func(opts *protoOptions) {
+		for _, p := range paths {
+			opts.redact = append(opts.redact, strings.Split(p, "."))
+		}
+	}
+}
+
+// WithProtoMaxBytes caps the encoded message at n bytes; larger ones are
+// logged as a string truncated on a rune boundary. Zero or less disables
+// the cap.
+// Default: 4096.
+func WithProtoMaxBytes(n int) ProtoOption {
+	return func(opts *protoOptions) {
+		opts.maxBytes = n
+	}
+}
+
+func ProtoOptions(options ...ProtoOption) *protoOptions {
+	opts := &protoOptions{maxBytes: 4096}
+	for _, opt := range options {
+		opt(opts)
+	}
+	return opts
+}
+
+// Proto encodes msg with protojson, using proto field names, instead of
+// dumping the internal fields of the generated struct. The message is only
+// encoded if the record is logged.
+//
+//	slog.Info("charge", protofields.Proto("request", req, protofields.WithProtoRedact("card.number")))
+func Proto(key string, msg proto.Message, options ...ProtoOption) slog.Attr {
+	return slog.Any(key, protoValue{msg: msg, opts: ProtoOptions(options...)})
+}
+
+type protoValue struct {
+	msg  proto.Message
+	opts *protoOptions
+}
+
+func (v protoValue) LogValue() slog.Value {
+	if v.msg == nil || !v.msg.ProtoReflect().IsValid() {
+		return slog.StringValue("<nil>")
+	}
+	b, err := protojson.MarshalOptions{UseProtoNames: true}.Marshal(v.msg)
+	if err != nil {
+		return slog.StringValue("<proto: " + err.Error() + ">")
+	}
+	if len(v.opts.redact) > 0 {
+		if b, err = redactJSON(b, v.opts.redact); err != nil {
+			return slog.StringValue("<proto: " + err.Error() + ">")
+		}
+	}
+	if n := v.opts.maxBytes; n > 0 && len(b) > n {
+		for n > 0 && !utf8.RuneStart(b[n]) {
+			n--
+		}
+		return slog.StringValue(string(b[:n]) + "...(" + strconv.Itoa(len(b)) + " bytes)")
+	}
+	return slog.AnyValue(rawJSON(b))
+}
+
+// rawJSON is written as is by JSON handlers and as a string by text ones.
+type rawJSON []byte
+
+func (b rawJSON) MarshalJSON() ([]byte, error) { return b, nil }
+
+func (b rawJSON) String() string { return string(b) }
+
+func redactJSON(b []byte, paths [][]string) ([]byte, error) {
+	d := json.NewDecoder(bytes.NewReader(b))
+	d.UseNumber()
+	var v any
+	if err := d.Decode(&v); err != nil {
+		return nil, err
+	}
+	for _, p := range paths {
+		redactPath(v, p)
+	}
+	return json.Marshal(v)
+}
+
+func redactPath(v any, path []string) {
+	switch v := v.(type) {
+	case []any:
+		for _, e := range v {
+			redactPath(e, path)
+		}
+	case map[string]any:
+		f, ok := v[path[0]]
+		if !ok {
+			return
+		}
+		if len(path) == 1 {
+			v[path[0]] = redacted
+			return
+		}
+		redactPath(f, path[1:])
+	}
+}
@@ -0,0 +1,335 @@
+//go:build !logger_lite && !logger_nofile
+
+package logger
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+func init() {
+	registerFeature("file")
+}
+
+const (
+	// RotateRename closes the file, renames it to the first backup and
+	// opens a new one. Closing first keeps it working on Windows, where an
+	// open file cannot be renamed; if the rename still fails, because another
+	// process holds the file, the rotation falls back to RotateCopyTruncate.
+	RotateRename string = "rename"
+	// RotateCopyTruncate copies the file to the first backup and truncates
+	// it in place, for NFS mounts and files that other processes keep open.
+	// Records written during the copy may be lost.
+	RotateCopyTruncate string = "copytruncate"
+)
+
+// FileOptions configure FileWriter. Backups are named Path.1 (newest) to
+// Path.MaxBackups. The file rotates once it would exceed MaxSize bytes, has
+// MaxRecords records (writes) or is older than MaxAge, and when Trigger,
+// checked before every write, returns true, e.g. after a config push. Zero
+// values disable a trigger.
+//
+// With a BatchInterval, writes are buffered and written with one syscall
+// every BatchInterval or once BatchSize bytes (default 64 KiB) are
+// buffered, for services where logging I/O is a measurable share of CPU.
+// Buffered records are lost if the process dies before a flush; a failed
+// flush is returned by the next Write.
+type FileOptions struct {
+	Path           string        `json:"path"`
+	MaxSize        int64         `json:"max_size,omitempty"`
+	MaxRecords     int64         `json:"max_records,omitempty"`
+	MaxAge         time.Duration `json:"max_age,omitempty"`
+	MaxBackups     int           `json:"max_backups,omitempty"`
+	RotateStrategy string        `json:"rotate_strategy,omitempty"`
+	BatchInterval  time.Duration `json:"batch_interval,omitempty"`
+	BatchSize      int           `json:"batch_size,omitempty"`
+	Trigger        func() bool   `json:"-"`
+}
+
+// FileWriter appends to a file and rotates it as configured by FileOptions.
+type FileWriter struct {
+	opts FileOptions
+
+	mu      sync.Mutex
+	f       *os.File
+	size    int64
+	records int64
+	opened  time.Time
+
+	batch    []byte
+	batchErr error
+	stop     chan struct{}
+	flusher  sync.WaitGroup
+}
+
+// w, err := logger.NewFileWriter(logger.FileOptions{Path: "app.log", MaxSize: 100 << 20, MaxBackups: 5})
+// logger.NewLogger(w, logger.WithJSON(true))
+func NewFileWriter(opts FileOptions) (*FileWriter, error) {
+	switch opts.RotateStrategy {
+	case "":
+		opts.RotateStrategy = RotateRename
+	case RotateRename, RotateCopyTruncate:
+	default:
+		return nil, fmt.Errorf("logger: unknown rotate strategy %q", opts.RotateStrategy)
+	}
+
+	if opts.BatchInterval > 0 && opts.BatchSize <= 0 {
+		opts.BatchSize = 64 << 10
+	}
+
+	w := &FileWriter{opts: opts}
+	if err := w.open(); err != nil {
+		return nil, err
+	}
+	if opts.BatchInterval > 0 {
+		w.stop = make(chan struct{})
+		w.flusher.Add(1)
+		go w.flushLoop(w.stop)
+	}
+	return w, nil
+}
+
+func (w *FileWriter) flushLoop(stop <-chan struct{}) {
+	defer w.flusher.Done()
+
+	t := time.NewTicker(w.opts.BatchInterval)
+	defer t.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-t.C:
+			w.mu.Lock()
+			if w.f != nil {
+				w.batchErr = errors.Join(w.batchErr, w.flush())
+			}
+			w.mu.Unlock()
+		}
+	}
+}
+
+// flush writes the buffered records. w.mu must be held.
+func (w *FileWriter) flush() error {
+	if len(w.batch) == 0 {
+		return nil
+	}
+	_, err := w.f.Write(w.batch)
+	w.batch = w.batch[:0]
+	return err
+}
+
+// Flush writes the records buffered with a BatchInterval.
+func (w *FileWriter) Flush() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.f == nil {
+		return os.ErrClosed
+	}
+	err := errors.Join(w.batchErr, w.flush())
+	w.batchErr = nil
+	return err
+}
+
+func (w *FileWriter) open() error {
+	f, err := os.OpenFile(w.opts.Path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	st, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	w.f, w.size, w.records, w.opened = f, st.Size(), 0, time.Now()
+	return nil
+}
+
+func (w *FileWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.f == nil {
+		return 0, os.ErrClosed
+	}
+	if w.due(len(p)) {
+		if err := w.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	if w.opts.BatchInterval > 0 {
+		if err := w.batchErr; err != nil {
+			w.batchErr = nil
+			return 0, err
+		}
+		w.batch = append(w.batch, p...)
+		w.size += int64(len(p))
+		w.records++
+		if len(w.batch) >= w.opts.BatchSize {
+			return len(p), w.flush()
+		}
+		return len(p), nil
+	}
+	n, err := w.f.Write(p)
+	w.size += int64(n)
+	w.records++
+	return n, err
+}
+
+// due reports whether the file should rotate before writing n bytes.
+func (w *FileWriter) due(n int) bool {
+	switch {
+	case w.opts.MaxSize > 0 && w.size > 0 && w.size+int64(n) > w.opts.MaxSize:
+		return true
+	case w.opts.MaxRecords > 0 && w.records >= w.opts.MaxRecords:
+		return true
+	case w.opts.MaxAge > 0 && w.size > 0 && time.Since(w.opened) >= w.opts.MaxAge:
+		return true
+	}
+	return w.opts.Trigger != nil && w.opts.Trigger()
+}
+
+// Rotate rotates the file now, for operational tooling.
+func (w *FileWriter) Rotate() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.f == nil {
+		return os.ErrClosed
+	}
+	return w.rotate()
+}
+
+// Reopen closes the file and opens Path again, for external rotation such
+// as logrotate moving the file away.
+func (w *FileWriter) Reopen() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.f == nil {
+		return os.ErrClosed
+	}
+	if err := w.flush(); err != nil {
+		return err
+	}
+	if err := w.f.Close(); err != nil {
+		return err
+	}
+	return w.open()
+}
+
+func (w *FileWriter) Close() error {
+	w.mu.Lock()
+	if w.f == nil {
+		w.mu.Unlock()
+		return nil
+	}
+	stop := w.stop
+	w.stop = nil
+	w.mu.Unlock()
+
+	if stop != nil {
+		close(stop)
+		w.flusher.Wait()
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.f == nil {
+		return nil
+	}
+	err := errors.Join(w.batchErr, w.flush(), w.f.Close())
+	w.f, w.batchErr = nil, nil
+	return err
+}
+
+func (w *FileWriter) backup(i int) string {
+	return fmt.Sprintf("%s.%d", w.opts.Path, i)
+}
+
+// shiftBackups moves Path.i to Path.i+1, dropping the oldest, so Path.1 is
+// free. Targets are removed first since Windows does not rename over an
+// existing file.
+func (w *FileWriter) shiftBackups() error {
+	if w.opts.MaxBackups < 1 {
+		return nil
+	}
+	if err := os.Remove(w.backup(w.opts.MaxBackups)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	for i := w.opts.MaxBackups - 1; i >= 1; i-- {
+		if err := os.Rename(w.backup(i), w.backup(i+1)); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+	return nil
+}
+
+func (w *FileWriter) rotate() error {
+	if err := w.flush(); err != nil {
+		return err
+	}
+	if err := w.shiftBackups(); err != nil {
+		return err
+	}
+	if w.opts.RotateStrategy == RotateCopyTruncate {
+		return w.copyTruncate()
+	}
+
+	if err := w.f.Close(); err != nil {
+		return err
+	}
+	if w.opts.MaxBackups < 1 {
+		err := os.Remove(w.opts.Path)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return errors.Join(err, w.open())
+		}
+		return w.open()
+	}
+	if err := os.Rename(w.opts.Path, w.backup(1)); err != nil {
+		if err := w.open(); err != nil {
+			return err
+		}
+		return w.copyTruncate()
+	}
+	return w.open()
+}
+
+func (w *FileWriter) copyTruncate() error {
+	if w.opts.MaxBackups > 0 {
+		if err := copyFile(w.opts.Path, w.backup(1)); err != nil {
+			return err
+		}
+	}
+	if err := w.f.Truncate(0); err != nil {
+		return err
+	}
+	if _, err := w.f.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	w.size, w.records, w.opened = 0, 0, time.Now()
+	return nil
+}
+
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
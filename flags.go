@@ -0,0 +1,61 @@
+package logger
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"time"
+)
+
+// Flags are the logging flags added by RegisterFlags.
+type Flags struct {
+	Config
+	File       string
+	SampleRate uint64
+}
+
+// RegisterFlags adds the logging flags to fs, or flag.CommandLine if fs is
+// nil: -log.level, -log.levels, -log.format, -log.time-format, -log.file
+// and -log.sample-rate.
+//
+//	lf := logger.RegisterFlags(nil)
+//	flag.Parse()
+//	h, err := lf.Build()
+func RegisterFlags(fs *flag.FlagSet) *Flags {
+	if fs == nil {
+		fs = flag.CommandLine
+	}
+	f := &Flags{}
+	fs.StringVar(&f.Level, "log.level", "info", "log `level`: debug, info, warn or error")
+	fs.StringVar(&f.Levels, "log.levels", "", "per-logger levels, e.g. payments.db=debug,http=warn")
+	fs.StringVar(&f.Format, "log.format", "text", "log `format`: text, json or fasttext")
+	fs.StringVar(&f.TimeFormat, "log.time-format", "", "time `layout` of records")
+	fs.StringVar(&f.File, "log.file", "", "append logs to `path` instead of stdout")
+	fs.Uint64Var(&f.SampleRate, "log.sample-rate", 0, "keep the first `n` records per level and message each second, then every nth; 0 keeps all")
+	return f
+}
+
+// Build returns a handler configured by the parsed flags on top of
+// options, setting the level and named levels. Its Close, also run by
+// Shutdown, closes the -log.file.
+func (f *Flags) Build(options ...Option) (*BuiltHandler, error) {
+	c := f.Config
+	if f.File != "" {
+		c.Outputs = []string{f.File}
+	}
+	h, closers, err := c.apply(options)
+	if err != nil {
+		return nil, fmt.Errorf("logger: flags: %w", err)
+	}
+	if f.SampleRate > 0 {
+		h = NewSamplingHandler(h, WithSampling(time.Second, f.SampleRate, f.SampleRate))
+	}
+	built := &BuiltHandler{Handler: h, closers: closers}
+	for _, c := range closers {
+		if w, ok := c.(io.Writer); ok {
+			built.outputs = append(built.outputs, w)
+		}
+	}
+	RegisterCloser(built)
+	return built, nil
+}
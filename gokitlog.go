@@ -1,38 +1,110 @@
+//go:build !logger_lite && !logger_nogokit
+
 package logger
 
 import (
 	"context"
+	"fmt"
 	"log/slog"
+	"runtime"
 	"strings"
 
 	gokitlog "github.com/go-kit/log"
 )
 
-type logFunc func(ctx context.Context, msg string, keysAndValues ...interface{})
+func init() {
+	registerFeature("gokit")
+}
+
+type gokitLogger struct {
+	logger *slog.Logger
+	level  slog.Level
+	skip   int
+}
+
+// Log maps go-kit keyvals to a record: "msg" or "message" becomes the
+// message, a "level" value as set by go-kit/log/level overrides the level,
+// "caller" is dropped in favour of the reported source, and "err" or
+// "error" is kept as "err". A repeated key keeps its last value.
+func (l gokitLogger) Log(keyvals ...interface{}) error {
+	ctx := SourceContext(context.Background(), gokitSource(l.skip))
 
-func (l logFunc) Log(keyvals ...interface{}) error {
-	ctx := SourceContext(context.Background(), CallerSource(2))
-	l(ctx, "", keyvals...)
+	level, msg := l.level, ""
+	attrs := make([]slog.Attr, 0, (len(keyvals)+1)/2)
+	for i := 0; i < len(keyvals); i += 2 {
+		key := fmt.Sprint(keyvals[i])
+		var v interface{} = gokitlog.ErrMissingValue
+		if i+1 < len(keyvals) {
+			v = keyvals[i+1]
+		}
 
+		switch key {
+		case "msg", "message":
+			msg = fmt.Sprint(v)
+			continue
+		case "level":
+			if lv, ok := gokitLevel(v); ok {
+				level = lv
+				continue
+			}
+		case "caller":
+			continue
+		case "err", "error":
+			key = "err"
+		}
+		attrs = setAttr(attrs, slog.Any(key, v))
+	}
+
+	if !l.logger.Enabled(ctx, level) {
+		return nil
+	}
+	l.logger.LogAttrs(ctx, level, msg, attrs...)
 	return nil
 }
 
+// gokitSource returns the first caller outside this adapter and go-kit/log,
+// which wraps loggers in log.With and level.Error, then skips skip more
+// frames.
+func gokitSource(skip int) *slog.Source {
+	return externalSource(skip, func(f runtime.Frame) bool {
+		return strings.HasPrefix(f.Function, gokitAdapterPrefix) ||
+			strings.HasPrefix(f.Function, gokitSourceDir+".") ||
+			strings.HasPrefix(f.Function, gokitSourceDir+"/")
+	})
+}
+
+const (
+	gokitSourceDir     = "github.com/go-kit/log"
+	gokitAdapterPrefix = "github.com/isauran/logger.gokit"
+)
+
+func gokitLevel(v interface{}) (slog.Level, bool) {
+	name := levelString(fmt.Sprint(v))
+	if name == "" {
+		return 0, false
+	}
+	return levelOf(name), true
+}
+
+// setAttr replaces the attr with the key of a in attrs, or appends a.
+func setAttr(attrs []slog.Attr, a slog.Attr) []slog.Attr {
+	for i := range attrs {
+		if attrs[i].Key == a.Key {
+			attrs[i] = a
+			return attrs
+		}
+	}
+	return append(attrs, a)
+}
+
 // logger.NewLogger(os.Stdout, logger.WithJSON(true))
 // logger := logger.NewGoKitLogger("info")
-func NewGoKitLogger(level string) gokitlog.Logger {
-	var logFunc logFunc
-	switch {
-	case strings.EqualFold(level, LevelDebug):
-		logFunc = slog.Default().DebugContext
-	case strings.EqualFold(level, LevelInfo):
-		logFunc = slog.Default().InfoContext
-	case strings.EqualFold(level, LevelWarn):
-		logFunc = slog.Default().WarnContext
-	case strings.EqualFold(level, LevelError):
-		logFunc = slog.Default().ErrorContext
-	default:
-		logFunc = slog.Default().InfoContext
+func NewGoKitLogger(level string, options ...Option) gokitlog.Logger {
+	opts := LoggerOptions(options...)
+	l := opts.logger
+	if l == nil {
+		l = slog.Default()
 	}
 
-	return logFunc
+	return gokitLogger{logger: l, level: parseLevel(level), skip: opts.callerSkip}
 }
@@ -1,3 +1,5 @@
+//go:build !logger_lite && !logger_nogorm
+
 package logger
 
 import (
@@ -5,41 +7,84 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
-	"strconv"
+	"runtime"
 	"strings"
 	"time"
 
 	"gorm.io/gorm/logger"
-	"gorm.io/gorm/utils"
 )
 
+func init() {
+	registerFeature("gorm")
+}
+
 var _ logger.Interface = (*gormLogger)(nil)
 
 // logger.NewLogger(os.Stdout, logger.WithJSON(true))
 // logger := logger.NewGormLogger("info")
-func NewGormLogger(level string) logger.Interface {
-	l := &gormLogger{}
+//
+// It honours WithLogger and WithCallerSkip and ignores the other options.
+//
+// Deprecated: without WithLogger the records go to the default logger, so
+// the adapter depends on NewLogger replacing it globally. Use
+// NewGormLoggerWith.
+func NewGormLogger(level string, options ...Option) logger.Interface {
+	opts := LoggerOptions(options...)
+	return NewGormLoggerWith(opts.logger, level, WithCallerSkip(opts.callerSkip))
+}
 
-	switch {
-	case strings.EqualFold(level, LevelDebug):
-		l.LogLevel = logger.Info
-	case strings.EqualFold(level, LevelInfo):
-		l.LogLevel = logger.Info
-	case strings.EqualFold(level, LevelWarn):
-		l.LogLevel = logger.Warn
-	case strings.EqualFold(level, LevelError):
-		l.LogLevel = logger.Error
+// NewGormLoggerWith returns a gorm logger writing to l, along with the
+// context passed to gorm, or to the default logger if l is nil. It honours
+// WithCallerSkip and ignores the other options.
+//
+//	db, err := gorm.Open(dialector, &gorm.Config{Logger: logger.NewGormLoggerWith(l, "info")})
+func NewGormLoggerWith(l *slog.Logger, level string, options ...Option) logger.Interface {
+	opts := LoggerOptions(options...)
+	gl := &gormLogger{skip: opts.callerSkip, logger: l}
+
+	switch levelString(level) {
+	case LevelDebug, LevelInfo:
+		gl.LogLevel = logger.Info
+	case LevelWarn:
+		gl.LogLevel = logger.Warn
+	case LevelError:
+		gl.LogLevel = logger.Error
 	default:
-		l.LogLevel = logger.Silent
+		gl.LogLevel = logger.Silent
 	}
 
-	return l
+	return gl
 }
 
 type gormLogger struct {
 	logger.Config
+	skip   int
+	logger *slog.Logger
 }
 
+func (l *gormLogger) slogger() *slog.Logger {
+	if l.logger != nil {
+		return l.logger
+	}
+	return slog.Default()
+}
+
+// gormSource returns the first caller outside gorm and this adapter, the
+// same frame utils.FileWithLineNum reports, then skips skip more frames.
+func gormSource(skip int) *slog.Source {
+	return externalSource(skip, func(f runtime.Frame) bool {
+		return strings.HasPrefix(f.Function, gormAdapterPrefix) || f.Function == gormSourceFunc ||
+			(strings.Contains(f.File, gormSourceDir) && !strings.HasSuffix(f.File, "_test.go")) ||
+			strings.HasSuffix(f.File, ".gen.go")
+	})
+}
+
+const (
+	gormSourceDir     = "gorm.io/gorm"
+	gormAdapterPrefix = "github.com/isauran/logger.(*gormLogger)"
+	gormSourceFunc    = "github.com/isauran/logger.gormSource"
+)
+
 // LogMode log mode
 func (l *gormLogger) LogMode(level logger.LogLevel) logger.Interface {
 	newlogger := *l
@@ -50,36 +95,27 @@ func (l *gormLogger) LogMode(level logger.LogLevel) logger.Interface {
 // Info print info
 func (l *gormLogger) Info(ctx context.Context, msg string, data ...interface{}) {
 	if l.LogLevel >= logger.Info {
-		fileLine := strings.Split(utils.FileWithLineNum(), ":")
-		file := fileLine[0]
-		line, _ := strconv.Atoi(fileLine[1])
-		ctx = SourceContext(ctx, &slog.Source{File: file, Line: line})
+		ctx = SourceContext(ctx, gormSource(l.skip))
 
-		slog.InfoContext(ctx, fmt.Sprintf(msg, data...))
+		l.slogger().InfoContext(ctx, fmt.Sprintf(msg, data...))
 	}
 }
 
 // Warn print warn messages
 func (l *gormLogger) Warn(ctx context.Context, msg string, data ...interface{}) {
 	if l.LogLevel >= logger.Warn {
-		fileLine := strings.Split(utils.FileWithLineNum(), ":")
-		file := fileLine[0]
-		line, _ := strconv.Atoi(fileLine[1])
-		ctx = SourceContext(ctx, &slog.Source{File: file, Line: line})
+		ctx = SourceContext(ctx, gormSource(l.skip))
 
-		slog.WarnContext(ctx, fmt.Sprintf(msg, data...))
+		l.slogger().WarnContext(ctx, fmt.Sprintf(msg, data...))
 	}
 }
 
 // Error print error messages
 func (l *gormLogger) Error(ctx context.Context, msg string, data ...interface{}) {
 	if l.LogLevel >= logger.Error {
-		fileLine := strings.Split(utils.FileWithLineNum(), ":")
-		file := fileLine[0]
-		line, _ := strconv.Atoi(fileLine[1])
-		ctx = SourceContext(ctx, &slog.Source{File: file, Line: line})
+		ctx = SourceContext(ctx, gormSource(l.skip))
 
-		slog.ErrorContext(ctx, fmt.Sprintf(msg, data...))
+		l.slogger().ErrorContext(ctx, fmt.Sprintf(msg, data...))
 	}
 }
 
@@ -87,38 +123,35 @@ func (l *gormLogger) Error(ctx context.Context, msg string, data ...interface{})
 //
 //nolint:cyclop
 func (l *gormLogger) Trace(ctx context.Context, begin time.Time, fc func() (string, int64), err error) {
-	fileLine := strings.Split(utils.FileWithLineNum(), ":")
-	file := fileLine[0]
-	line, _ := strconv.Atoi(fileLine[1])
-	ctx = SourceContext(ctx, &slog.Source{File: file, Line: line})
+	ctx = SourceContext(ctx, gormSource(l.skip))
 
 	if l.LogLevel <= logger.Silent {
 		return
 	}
 
 	elapsed := time.Since(begin)
+	var (
+		level slog.Level
+		msg   string
+	)
 	switch {
 	case err != nil && l.LogLevel >= logger.Error && (!errors.Is(err, logger.ErrRecordNotFound) || !l.IgnoreRecordNotFoundError):
-		sql, rows := fc()
-		if rows == -1 {
-			slog.ErrorContext(ctx, err.Error(), "ms", fmt.Sprintf("%.3f", float64(elapsed.Nanoseconds())/1e6), "sql", sql)
-		} else {
-			slog.ErrorContext(ctx, err.Error(), "ms", fmt.Sprintf("%.3f", float64(elapsed.Nanoseconds())/1e6), "rows", rows, "sql", sql)
-		}
+		level, msg = slog.LevelError, err.Error()
 	case elapsed > l.SlowThreshold && l.SlowThreshold != 0 && l.LogLevel >= logger.Warn:
-		sql, rows := fc()
-		slowLog := fmt.Sprintf("SLOW SQL >= %v", l.SlowThreshold)
-		if rows == -1 {
-			slog.WarnContext(ctx, slowLog, "ms", fmt.Sprintf("%.3f", float64(elapsed.Nanoseconds())/1e6), "sql", sql)
-		} else {
-			slog.WarnContext(ctx, slowLog, "ms", fmt.Sprintf("%.3f", float64(elapsed.Nanoseconds())/1e6), "rows", rows, "sql", sql)
-		}
+		level, msg = slog.LevelWarn, fmt.Sprintf("SLOW SQL >= %v", l.SlowThreshold)
 	case l.LogLevel == logger.Info:
-		sql, rows := fc()
-		if rows == -1 {
-			slog.InfoContext(ctx, "", "ms", fmt.Sprintf("%.3f", float64(elapsed.Nanoseconds())/1e6), "sql", sql)
-		} else {
-			slog.InfoContext(ctx, "", "ms", fmt.Sprintf("%.3f", float64(elapsed.Nanoseconds())/1e6), "rows", rows, "sql", sql)
-		}
+		level = slog.LevelInfo
+	default:
+		return
+	}
+
+	sql, rows := fc()
+	attrs := GetAttrs()
+	defer ReleaseAttrs(attrs)
+	*attrs = append(*attrs, slog.String("ms", fmt.Sprintf("%.3f", float64(elapsed.Nanoseconds())/1e6)))
+	if rows != -1 {
+		*attrs = append(*attrs, slog.Int64("rows", rows))
 	}
+	*attrs = append(*attrs, slog.String("sql", sql))
+	l.slogger().LogAttrs(ctx, level, msg, *attrs...)
 }
@@ -0,0 +1,202 @@
+package logger
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+type GuardOption func(*guardOptions)
+
+type guardOptions struct {
+	cooldown  time.Duration
+	queueSize int
+	drop      bool
+	notify    slog.Handler
+}
+
+// WithGuardCooldown sets how long a slow sink stays degraded. Default: 10s.
+func WithGuardCooldown(cooldown time.Duration) GuardOption {
+	return func(opts *guardOptions) {
+		opts.cooldown = cooldown
+	}
+}
+
+// WithGuardQueue sets the size of the async queue used while degraded.
+func WithGuardQueue(size int) GuardOption {
+	return func(opts *guardOptions) {
+		opts.queueSize = size
+	}
+}
+
+// WithGuardDrop drops records while degraded instead of queueing them.
+func WithGuardDrop() GuardOption {
+	return func(opts *guardOptions) {
+		opts.drop = true
+	}
+}
+
+// WithGuardNotify logs the warning emitted when the sink exceeds its budget
+// to h instead of as text to os.Stderr.
+func WithGuardNotify(h slog.Handler) GuardOption {
+	return func(opts *guardOptions) {
+		opts.notify = h
+	}
+}
+
+func GuardOptions(options ...GuardOption) *guardOptions {
+	opts := &guardOptions{
+		cooldown:  10 * time.Second,
+		queueSize: 1024,
+	}
+	for _, opt := range options {
+		opt(opts)
+	}
+	if opts.notify == nil {
+		opts.notify = slog.NewTextHandler(os.Stderr, nil)
+	}
+	return opts
+}
+
+// LatencyGuardHandler keeps a slow sink from adding latency to callers. When
+// a Handle call takes longer than budget the sink is degraded for the
+// cooldown: records are handed to a background goroutine (or dropped with
+// WithGuardDrop, or when the queue is full) and a WARN record is logged to
+// the WithGuardNotify handler. Close stops the background goroutine.
+type LatencyGuardHandler struct {
+	slog.Handler
+	guard *latencyGuard
+}
+
+type latencyGuard struct {
+	budget time.Duration
+	opts   *guardOptions
+
+	degradedUntil atomic.Int64
+	dropped       atomic.Uint64
+	pending       atomic.Int64
+
+	mu     sync.RWMutex
+	closed bool
+	once   sync.Once
+	queue  chan guardItem
+	done   chan struct{}
+}
+
+type guardItem struct {
+	ctx context.Context
+	h   slog.Handler
+	r   slog.Record
+}
+
+// logger.NewLatencyGuardHandler(networkHandler, 5*time.Millisecond, logger.WithGuardCooldown(time.Minute))
+func NewLatencyGuardHandler(h slog.Handler, budget time.Duration, options ...GuardOption) *LatencyGuardHandler {
+	return &LatencyGuardHandler{
+		Handler: h,
+		guard:   &latencyGuard{budget: budget, opts: GuardOptions(options...)},
+	}
+}
+
+// Dropped returns the number of records dropped while degraded.
+func (h *LatencyGuardHandler) Dropped() uint64 {
+	return h.guard.dropped.Load()
+}
+
+// QueueDepth returns the number of records waiting in the queue.
+func (h *LatencyGuardHandler) QueueDepth() int {
+	return int(h.guard.pending.Load())
+}
+
+func (h *LatencyGuardHandler) Handle(ctx context.Context, r slog.Record) error {
+	g := h.guard
+	// Records keep going through the queue until it drains, so they are
+	// not written ahead of the ones queued while degraded.
+	if time.Now().UnixNano() < g.degradedUntil.Load() || g.pending.Load() > 0 {
+		g.enqueue(ctx, h.Handler, r.Clone())
+		return nil
+	}
+
+	begin := time.Now()
+	err := h.Handler.Handle(ctx, r)
+	elapsed := time.Since(begin)
+
+	if elapsed > g.budget {
+		until := time.Now().Add(g.opts.cooldown)
+		g.degradedUntil.Store(until.UnixNano())
+
+		if g.opts.notify.Enabled(ctx, slog.LevelWarn) {
+			warn := slog.NewRecord(time.Now(), slog.LevelWarn, "log sink exceeded latency budget", 0)
+			warn.AddAttrs(
+				slog.Duration("elapsed", elapsed),
+				slog.Duration("budget", g.budget),
+				slog.Time("degraded_until", until),
+				slog.Bool("drop", g.opts.drop),
+			)
+			_ = g.opts.notify.Handle(ctx, warn)
+		}
+	}
+	return err
+}
+
+// Close waits for the queued records to be handled and stops the
+// background goroutine. Records handled after Close while degraded are
+// dropped.
+func (h *LatencyGuardHandler) Close() error {
+	g := h.guard
+	g.mu.Lock()
+	if g.closed {
+		g.mu.Unlock()
+		return nil
+	}
+	g.closed = true
+	if g.queue != nil {
+		close(g.queue)
+	}
+	g.mu.Unlock()
+
+	if g.done != nil {
+		<-g.done
+	}
+	return nil
+}
+
+func (h *LatencyGuardHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &LatencyGuardHandler{Handler: h.Handler.WithAttrs(attrs), guard: h.guard}
+}
+
+func (h *LatencyGuardHandler) WithGroup(name string) slog.Handler {
+	return &LatencyGuardHandler{Handler: h.Handler.WithGroup(name), guard: h.guard}
+}
+
+func (g *latencyGuard) enqueue(ctx context.Context, h slog.Handler, r slog.Record) {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	if g.opts.drop || g.closed {
+		g.dropped.Add(1)
+		return
+	}
+
+	g.once.Do(func() {
+		g.queue = make(chan guardItem, g.opts.queueSize)
+		g.done = make(chan struct{})
+		go func() {
+			defer close(g.done)
+			for item := range g.queue {
+				_ = item.h.Handle(item.ctx, item.r)
+				g.pending.Add(-1)
+			}
+		}()
+	})
+
+	g.pending.Add(1)
+	select {
+	case g.queue <- guardItem{ctx: context.WithoutCancel(ctx), h: h, r: r}:
+	default:
+		g.pending.Add(-1)
+		g.dropped.Add(1)
+	}
+}
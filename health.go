@@ -0,0 +1,239 @@
+package logger
+
+import (
+	"errors"
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+var (
+	// ErrWriteTimeout is returned by HealthWriter and BreakerHandler for a
+	// write that did not finish in time.
+	ErrWriteTimeout = errors.New("logger: write timed out")
+	// ErrWriterOpen is returned by HealthWriter without writing while its
+	// circuit breaker is open.
+	ErrWriterOpen = errors.New("logger: writer circuit open")
+)
+
+type HealthOption func(*healthOptions)
+
+type healthOptions struct {
+	timeout   time.Duration
+	threshold int
+	cooldown  time.Duration
+	retries   int
+	backoff   time.Duration
+}
+
+// WithWriteTimeout fails writes taking longer than d. The write goes on in
+// the background, and later writes fail fast until it returns. Writes
+// waiting for a write in flight that has not timed out yet wait up to d.
+func WithWriteTimeout(d time.Duration) HealthOption {
+	return func(opts *healthOptions) {
+		opts.timeout = d
+	}
+}
+
+// WithCircuitBreaker opens the circuit after threshold consecutive failed
+// writes, dropping writes for cooldown before probing the writer again with
+// a single write.
+func WithCircuitBreaker(threshold int, cooldown time.Duration) HealthOption {
+	return func(opts *healthOptions) {
+		opts.threshold = threshold
+		opts.cooldown = cooldown
+	}
+}
+
+// WithWriteRetries retries a failed write up to n times, waiting backoff
+// before the first retry and twice as long before each next one. Timed-out
+// and partial writes are not retried.
+func WithWriteRetries(n int, backoff time.Duration) HealthOption {
+	return func(opts *healthOptions) {
+		opts.retries = n
+		opts.backoff = backoff
+	}
+}
+
+func HealthOptions(options ...HealthOption) *healthOptions {
+	opts := &healthOptions{
+		timeout:   time.Second,
+		threshold: 5,
+		cooldown:  10 * time.Second,
+	}
+	for _, opt := range options {
+		opt(opts)
+	}
+	return opts
+}
+
+// Breaker states reported by HealthWriter.State and BreakerHandler.State.
+const (
+	BreakerClosed   string = "closed"
+	BreakerOpen     string = "open"
+	BreakerHalfOpen string = "half-open"
+)
+
+// HealthWriter protects the process from a writer that hangs or keeps
+// failing, e.g. on a stuck NFS mount or a full pipe, so it cannot block
+// every log call.
+type HealthWriter struct {
+	w    io.Writer
+	opts *healthOptions
+	// busy holds a token while a write runs in the background.
+	busy chan struct{}
+	// stuck counts the writes that timed out and have not returned.
+	stuck atomic.Int64
+
+	breaker *breaker
+}
+
+// w := logger.NewHealthWriter(nfsFile, logger.WithWriteTimeout(100*time.Millisecond))
+func NewHealthWriter(w io.Writer, options ...HealthOption) *HealthWriter {
+	opts := HealthOptions(options...)
+	return &HealthWriter{
+		w:       w,
+		opts:    opts,
+		busy:    make(chan struct{}, 1),
+		breaker: newBreaker(opts.threshold, opts.cooldown),
+	}
+}
+
+// State returns BreakerClosed, BreakerOpen or BreakerHalfOpen.
+func (w *HealthWriter) State() string {
+	return w.breaker.State()
+}
+
+func (w *HealthWriter) Write(p []byte) (int, error) {
+	if !w.breaker.allow() {
+		return 0, ErrWriterOpen
+	}
+
+	backoff := w.opts.backoff
+	for attempt := 0; ; attempt++ {
+		n, err := w.write(p)
+		if err == nil {
+			w.breaker.done(true)
+			return n, nil
+		}
+		if attempt >= w.opts.retries || n > 0 || errors.Is(err, ErrWriteTimeout) {
+			w.breaker.done(false)
+			return n, err
+		}
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+}
+
+func (w *HealthWriter) write(p []byte) (int, error) {
+	if w.opts.timeout <= 0 {
+		return w.w.Write(p)
+	}
+
+	if w.stuck.Load() > 0 {
+		return 0, ErrWriteTimeout
+	}
+	timer := time.NewTimer(w.opts.timeout)
+	defer timer.Stop()
+	select {
+	case w.busy <- struct{}{}:
+	case <-timer.C:
+		return 0, ErrWriteTimeout
+	}
+
+	// The caller may reuse p once Write returns, while the write goes on.
+	buf := append([]byte(nil), p...)
+	type result struct {
+		n   int
+		err error
+	}
+	done := make(chan result, 1)
+	var state atomic.Int32
+	go func() {
+		defer func() { <-w.busy }()
+		n, err := w.w.Write(buf)
+		if !state.CompareAndSwap(callRunning, callDone) {
+			w.stuck.Add(-1)
+		}
+		done <- result{n, err}
+	}()
+
+	select {
+	case r := <-done:
+		return checkWrite(r.n, r.err, len(p))
+	case <-timer.C:
+		w.stuck.Add(1)
+		if state.CompareAndSwap(callRunning, callTimedOut) {
+			return 0, ErrWriteTimeout
+		}
+		w.stuck.Add(-1)
+		r := <-done
+		return checkWrite(r.n, r.err, len(p))
+	}
+}
+
+func checkWrite(n int, err error, size int) (int, error) {
+	if err == nil && n < size {
+		err = io.ErrShortWrite
+	}
+	return n, err
+}
+
+// breaker is a circuit breaker opening after threshold consecutive
+// failures, zero meaning never, and letting a single probe through once
+// cooldown passed.
+type breaker struct {
+	threshold int
+	cooldown  time.Duration
+
+	mu       sync.Mutex
+	state    string
+	failures int
+	openedAt time.Time
+}
+
+func newBreaker(threshold int, cooldown time.Duration) *breaker {
+	return &breaker{threshold: threshold, cooldown: cooldown, state: BreakerClosed}
+}
+
+func (b *breaker) State() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.state
+}
+
+// allow reports whether a call may go ahead, letting a single probe through
+// once the cooldown of an open circuit passed.
+func (b *breaker) allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case BreakerOpen:
+		if time.Since(b.openedAt) < b.cooldown {
+			return false
+		}
+		b.state = BreakerHalfOpen
+		return true
+	case BreakerHalfOpen:
+		return false
+	}
+	return true
+}
+
+func (b *breaker) done(ok bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if ok {
+		b.state = BreakerClosed
+		b.failures = 0
+		return
+	}
+	b.failures++
+	if b.state == BreakerHalfOpen || (b.threshold > 0 && b.failures >= b.threshold) {
+		b.state = BreakerOpen
+		b.openedAt = time.Now()
+	}
+}
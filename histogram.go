@@ -0,0 +1,171 @@
+package logger
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// histogramLevels are the levels LevelHistogram counts; records count at
+// the highest one not above their level.
+var histogramLevels = []string{LevelDebug, LevelInfo, LevelWarn, LevelError, LevelFatal, LevelPanic}
+
+func histogramIndex(level slog.Level) int {
+	switch {
+	case level < slog.LevelInfo:
+		return 0
+	case level < slog.LevelWarn:
+		return 1
+	case level < slog.LevelError:
+		return 2
+	case level < FatalLevel:
+		return 3
+	case level < PanicLevel:
+		return 4
+	default:
+		return 5
+	}
+}
+
+// LevelCounts are the records of one minute by level name.
+type LevelCounts struct {
+	Minute time.Time      `json:"minute"`
+	Counts map[string]int `json:"counts"`
+}
+
+// LevelHistogramReport is a LevelHistogram snapshot with a sparkline per
+// level that has records.
+type LevelHistogramReport struct {
+	Minutes    []LevelCounts     `json:"minutes"`
+	Sparklines map[string]string `json:"sparklines"`
+}
+
+// LevelHistogram counts records per minute and level over the last
+// minutes, for a quick look at error bursts without a metrics stack.
+type LevelHistogram struct {
+	mu      sync.Mutex
+	buckets []levelBucket
+	last    int64
+}
+
+type levelBucket struct {
+	minute int64
+	counts [6]int
+}
+
+// hist := logger.NewLevelHistogram(60)
+// logger.NewControlHandler(logger.WithControlHistogram(hist))
+func NewLevelHistogram(minutes int) *LevelHistogram {
+	return &LevelHistogram{buckets: make([]levelBucket, max(minutes, 1))}
+}
+
+// Add counts a record at t. Records older than the kept minutes, relative
+// to the newest one added, are dropped.
+func (h *LevelHistogram) Add(t time.Time, level slog.Level) {
+	minute := t.Unix() / 60
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if minute <= h.last-int64(len(h.buckets)) {
+		return
+	}
+	h.last = max(h.last, minute)
+	b := &h.buckets[minute%int64(len(h.buckets))]
+	if b.minute != minute {
+		*b = levelBucket{minute: minute}
+	}
+	b.counts[histogramIndex(level)]++
+}
+
+// Snapshot returns the counts of the kept minutes, oldest first, up to the
+// newest one added.
+func (h *LevelHistogram) Snapshot() []LevelCounts {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.last == 0 {
+		return nil
+	}
+	out := make([]LevelCounts, 0, len(h.buckets))
+	for minute := h.last - int64(len(h.buckets)) + 1; minute <= h.last; minute++ {
+		c := LevelCounts{Minute: time.Unix(minute*60, 0), Counts: map[string]int{}}
+		if b := h.buckets[minute%int64(len(h.buckets))]; b.minute == minute {
+			for i, n := range b.counts {
+				if n > 0 {
+					c.Counts[histogramLevels[i]] = n
+				}
+			}
+		}
+		out = append(out, c)
+	}
+	return out
+}
+
+// Report returns the snapshot with its sparklines.
+func (h *LevelHistogram) Report() LevelHistogramReport {
+	minutes := h.Snapshot()
+	report := LevelHistogramReport{Minutes: minutes, Sparklines: map[string]string{}}
+	for _, level := range histogramLevels {
+		counts := make([]int, len(minutes))
+		total := 0
+		for i, m := range minutes {
+			counts[i] = m.Counts[level]
+			total += counts[i]
+		}
+		if total > 0 {
+			report.Sparklines[level] = Sparkline(counts)
+		}
+	}
+	return report
+}
+
+var sparks = []rune("▁▂▃▄▅▆▇█")
+
+// Sparkline renders counts as block characters scaled to the largest,
+// with a space for zero.
+func Sparkline(counts []int) string {
+	top := 0
+	for _, n := range counts {
+		top = max(top, n)
+	}
+	out := make([]rune, len(counts))
+	for i, n := range counts {
+		switch {
+		case n == 0:
+			out[i] = ' '
+		default:
+			out[i] = sparks[(n*len(sparks)-1)/top]
+		}
+	}
+	return string(out)
+}
+
+// LevelHistogramHandler counts the records it handles in a LevelHistogram.
+type LevelHistogramHandler struct {
+	slog.Handler
+	hist *LevelHistogram
+}
+
+// logger.NewLevelHistogramHandler(h, hist)
+func NewLevelHistogramHandler(h slog.Handler, hist *LevelHistogram) *LevelHistogramHandler {
+	return &LevelHistogramHandler{Handler: h, hist: hist}
+}
+
+func (h *LevelHistogramHandler) Handle(ctx context.Context, r slog.Record) error {
+	t := r.Time
+	if t.IsZero() {
+		t = time.Now()
+	}
+	h.hist.Add(t, r.Level)
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h *LevelHistogramHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &LevelHistogramHandler{Handler: h.Handler.WithAttrs(attrs), hist: h.hist}
+}
+
+func (h *LevelHistogramHandler) WithGroup(name string) slog.Handler {
+	return &LevelHistogramHandler{Handler: h.Handler.WithGroup(name), hist: h.hist}
+}
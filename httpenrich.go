@@ -0,0 +1,149 @@
+//go:build !logger_lite && !logger_nohttp
+
+package logger
+
+import (
+	"log/slog"
+	"net/http"
+	"net/netip"
+	"strings"
+)
+
+// Enricher adds attrs describing the client of a request. Attrs returned by
+// all enrichers are logged by the HTTP middleware inside a "client" group.
+type Enricher interface {
+	Enrich(r *http.Request, clientIP string) []slog.Attr
+}
+
+type EnricherFunc func(r *http.Request, clientIP string) []slog.Attr
+
+func (f EnricherFunc) Enrich(r *http.Request, clientIP string) []slog.Attr {
+	return f(r, clientIP)
+}
+
+func WithEnrichers(enrichers ...Enricher) HTTPOption {
+	return func(opts *httpOptions) {
+		opts.enrichers = append(opts.enrichers, enrichers...)
+	}
+}
+
+// UserAgentEnricher parses the User-Agent header into browser, browser_version,
+// os, mobile and bot attrs.
+func UserAgentEnricher() Enricher {
+	return EnricherFunc(func(r *http.Request, _ string) []slog.Attr {
+		ua := r.UserAgent()
+		if ua == "" {
+			return nil
+		}
+		browser, version := parseBrowser(ua)
+		return []slog.Attr{
+			slog.String("browser", browser),
+			slog.String("browser_version", version),
+			slog.String("os", parseOS(ua)),
+			slog.Bool("mobile", strings.Contains(ua, "Mobile")),
+			slog.Bool("bot", isBot(ua)),
+		}
+	})
+}
+
+var uaBrowsers = []struct{ token, name string }{
+	{"Edg/", "Edge"},
+	{"OPR/", "Opera"},
+	{"SamsungBrowser/", "Samsung Internet"},
+	{"Firefox/", "Firefox"},
+	{"CriOS/", "Chrome"},
+	{"Chrome/", "Chrome"},
+	{"Version/", "Safari"},
+	{"MSIE ", "Internet Explorer"},
+	{"Trident/", "Internet Explorer"},
+	{"curl/", "curl"},
+	{"Wget/", "Wget"},
+	{"Go-http-client/", "Go"},
+	{"python-requests/", "python-requests"},
+}
+
+func parseBrowser(ua string) (string, string) {
+	for _, b := range uaBrowsers {
+		i := strings.Index(ua, b.token)
+		if i < 0 {
+			continue
+		}
+		v := ua[i+len(b.token):]
+		if end := strings.IndexAny(v, " ;)"); end >= 0 {
+			v = v[:end]
+		}
+		if major, _, ok := strings.Cut(v, "."); ok {
+			v = major
+		}
+		return b.name, v
+	}
+	return "unknown", ""
+}
+
+var uaOS = []struct{ token, name string }{
+	{"Windows", "Windows"},
+	{"Android", "Android"},
+	{"iPhone", "iOS"},
+	{"iPad", "iOS"},
+	{"CrOS", "ChromeOS"},
+	{"Mac OS X", "macOS"},
+	{"Linux", "Linux"},
+}
+
+func parseOS(ua string) string {
+	for _, o := range uaOS {
+		if strings.Contains(ua, o.token) {
+			return o.name
+		}
+	}
+	return "unknown"
+}
+
+var uaBots = []string{"bot", "crawl", "spider", "slurp", "headless", "lighthouse", "curl/", "wget/", "python-requests/", "go-http-client/"}
+
+func isBot(ua string) bool {
+	ua = strings.ToLower(ua)
+	for _, b := range uaBots {
+		if strings.Contains(ua, b) {
+			return true
+		}
+	}
+	return false
+}
+
+type GeoInfo struct {
+	Country string
+	Region  string
+	City    string
+}
+
+// GeoIP is implemented by GeoIP databases, e.g. a MaxMind reader wrapper.
+type GeoIP interface {
+	Lookup(ip netip.Addr) (GeoInfo, bool)
+}
+
+// GeoEnricher adds country, region and city attrs for public client IPs.
+func GeoEnricher(db GeoIP) Enricher {
+	return EnricherFunc(func(_ *http.Request, clientIP string) []slog.Attr {
+		ip, err := netip.ParseAddr(clientIP)
+		if err != nil || ip.IsPrivate() || ip.IsLoopback() {
+			return nil
+		}
+		geo, ok := db.Lookup(ip)
+		if !ok {
+			return nil
+		}
+
+		var attrs []slog.Attr
+		if geo.Country != "" {
+			attrs = append(attrs, slog.String("country", geo.Country))
+		}
+		if geo.Region != "" {
+			attrs = append(attrs, slog.String("region", geo.Region))
+		}
+		if geo.City != "" {
+			attrs = append(attrs, slog.String("city", geo.City))
+		}
+		return attrs
+	})
+}
@@ -0,0 +1,225 @@
+//go:build !logger_lite && !logger_nohttp
+
+package logger
+
+import (
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+	"time"
+)
+
+func init() {
+	registerFeature("http")
+}
+
+type HTTPOption func(*httpOptions)
+
+type httpOptions struct {
+	trusted   []netip.Prefix
+	headers   []string
+	enrichers []Enricher
+	logger    *slog.Logger
+}
+
+// WithTrustedProxies sets the proxy addresses (IPs or CIDRs) whose
+// forwarding headers are believed. Without it, and for requests from any
+// other peer, client_ip is the peer address, so clients can't spoof their IP.
+func WithTrustedProxies(proxies ...string) HTTPOption {
+	return func(opts *httpOptions) {
+		for _, p := range proxies {
+			if prefix, err := netip.ParsePrefix(p); err == nil {
+				opts.trusted = append(opts.trusted, prefix.Masked())
+				continue
+			}
+			if addr, err := netip.ParseAddr(p); err == nil {
+				opts.trusted = append(opts.trusted, netip.PrefixFrom(addr, addr.BitLen()))
+			}
+		}
+	}
+}
+
+// WithClientIPHeaders sets the headers checked, in order, for the client IP
+// when the peer is a trusted proxy. Default: Forwarded, X-Forwarded-For.
+// Only list headers the trusted proxies overwrite, e.g. CF-Connecting-IP
+// behind Cloudflare:
+//
+//	logger.WithTrustedProxies(cloudflareRanges...),
+//	logger.WithClientIPHeaders("CF-Connecting-IP")
+func WithClientIPHeaders(headers ...string) HTTPOption {
+	return func(opts *httpOptions) {
+		opts.headers = headers
+	}
+}
+
+// WithHTTPLogger makes the middleware log to l instead of the default logger.
+func WithHTTPLogger(l *slog.Logger) HTTPOption {
+	return func(opts *httpOptions) {
+		opts.logger = l
+	}
+}
+
+func HTTPOptions(options ...HTTPOption) *httpOptions {
+	opts := &httpOptions{}
+	for _, opt := range options {
+		opt(opts)
+	}
+	if opts.headers == nil && len(opts.trusted) > 0 {
+		opts.headers = []string{"Forwarded", "X-Forwarded-For"}
+	}
+	return opts
+}
+
+// logger.NewLogger(os.Stdout, logger.WithJSON(true))
+// http.ListenAndServe(":8080", logger.NewHTTPMiddleware(mux, logger.WithTrustedProxies("10.0.0.0/8")))
+func NewHTTPMiddleware(next http.Handler, options ...HTTPOption) http.Handler {
+	opts := HTTPOptions(options...)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		begin := time.Now()
+		rw := &responseWriter{ResponseWriter: w, status: http.StatusOK}
+
+		next.ServeHTTP(rw, r)
+
+		level := slog.LevelInfo
+		switch {
+		case rw.status >= 500:
+			level = slog.LevelError
+		case rw.status >= 400:
+			level = slog.LevelWarn
+		}
+		clientIP := opts.clientIP(r)
+		attrs := GetAttrs()
+		defer ReleaseAttrs(attrs)
+		*attrs = append(*attrs,
+			slog.String("method", r.Method),
+			slog.String("path", r.URL.Path),
+			slog.Int("status", rw.status),
+			slog.Int("bytes", rw.bytes),
+			slog.String("ms", fmt.Sprintf("%.3f", float64(time.Since(begin).Nanoseconds())/1e6)),
+			slog.String("client_ip", clientIP),
+		)
+		if len(opts.enrichers) > 0 {
+			var client []any
+			for _, e := range opts.enrichers {
+				for _, a := range e.Enrich(r, clientIP) {
+					client = append(client, a)
+				}
+			}
+			if len(client) > 0 {
+				*attrs = append(*attrs, slog.Group("client", client...))
+			}
+		}
+		l := opts.logger
+		if l == nil {
+			l = slog.Default()
+		}
+		l.LogAttrs(r.Context(), level, "", *attrs...)
+	})
+}
+
+func (opts *httpOptions) isTrusted(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	for _, p := range opts.trusted {
+		if p.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+func (opts *httpOptions) clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	peer, err := netip.ParseAddr(host)
+	if err != nil || !opts.isTrusted(peer) {
+		return host
+	}
+
+	for _, h := range opts.headers {
+		values := r.Header.Values(h)
+		if len(values) == 0 {
+			continue
+		}
+
+		var chain []string
+		switch strings.ToLower(h) {
+		case "forwarded":
+			chain = parseForwarded(values)
+		case "x-forwarded-for":
+			for _, v := range values {
+				for _, ip := range strings.Split(v, ",") {
+					chain = append(chain, strings.TrimSpace(ip))
+				}
+			}
+		default:
+			chain = []string{strings.TrimSpace(values[0])}
+		}
+
+		// walk right to left: the first hop not added by a trusted proxy is the client
+		for i := len(chain) - 1; i >= 0; i-- {
+			addr, err := netip.ParseAddr(chain[i])
+			if err != nil {
+				break
+			}
+			if i == 0 || !opts.isTrusted(addr) {
+				return addr.Unmap().String()
+			}
+		}
+	}
+	return host
+}
+
+// parseForwarded returns the "for" values of RFC 7239 Forwarded headers.
+func parseForwarded(values []string) []string {
+	var chain []string
+	for _, v := range values {
+		for _, elem := range strings.Split(v, ",") {
+			for _, pair := range strings.Split(elem, ";") {
+				k, val, ok := strings.Cut(strings.TrimSpace(pair), "=")
+				if !ok || !strings.EqualFold(k, "for") {
+					continue
+				}
+				val = strings.Trim(val, `"`)
+				if strings.HasPrefix(val, "[") {
+					val = strings.TrimPrefix(val[:strings.Index(val+"]", "]")], "[")
+				} else if host, _, err := net.SplitHostPort(val); err == nil {
+					val = host
+				}
+				chain = append(chain, val)
+			}
+		}
+	}
+	return chain
+}
+
+type responseWriter struct {
+	http.ResponseWriter
+	status      int
+	bytes       int
+	wroteHeader bool
+}
+
+func (w *responseWriter) WriteHeader(status int) {
+	if !w.wroteHeader {
+		w.status = status
+		w.wroteHeader = true
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *responseWriter) Write(p []byte) (int, error) {
+	w.wroteHeader = true
+	n, err := w.ResponseWriter.Write(p)
+	w.bytes += n
+	return n, err
+}
+
+func (w *responseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,174 @@
+package logger
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"math"
+	"strconv"
+	"time"
+	"unicode/utf8"
+)
+
+// The JSON encoder below appends to a byte slice in attr order and only
+// falls back to encoding/json for values of types it does not know.
+
+var jsonBufPool = newBufferPool()
+
+// appendJSONSep appends the comma separating a member from the previous
+// one, unless buf is at the start of an object.
+func appendJSONSep(buf []byte) []byte {
+	if len(buf) == 0 || buf[len(buf)-1] != '{' {
+		return append(buf, ',')
+	}
+	return buf
+}
+
+// appendJSONAttr appends a as an object member, following slog: groups
+// are nested, members of groups with an empty key are inlined, and empty
+// attrs and groups are omitted.
+func appendJSONAttr(buf []byte, a slog.Attr) []byte {
+	v := a.Value.Resolve()
+	if v.Kind() == slog.KindGroup {
+		members := v.Group()
+		if emptyAttrs(members) {
+			return buf
+		}
+		if a.Key != "" {
+			buf = appendJSONSep(buf)
+			buf = appendJSONString(buf, a.Key)
+			buf = append(buf, ":{"...)
+		}
+		for _, ga := range members {
+			buf = appendJSONAttr(buf, ga)
+		}
+		if a.Key != "" {
+			buf = append(buf, '}')
+		}
+		return buf
+	}
+	if a.Key == "" {
+		return buf
+	}
+	buf = appendJSONSep(buf)
+	buf = appendJSONString(buf, a.Key)
+	buf = append(buf, ':')
+	return appendJSONValue(buf, v)
+}
+
+// emptyAttrs reports whether attrs would add no members.
+func emptyAttrs(attrs []slog.Attr) bool {
+	for _, a := range attrs {
+		v := a.Value.Resolve()
+		if v.Kind() == slog.KindGroup {
+			if !emptyAttrs(v.Group()) {
+				return false
+			}
+			continue
+		}
+		if a.Key != "" {
+			return false
+		}
+	}
+	return true
+}
+
+func appendJSONValue(buf []byte, v slog.Value) []byte {
+	switch v.Kind() {
+	case slog.KindString:
+		return appendJSONString(buf, v.String())
+	case slog.KindInt64:
+		return strconv.AppendInt(buf, v.Int64(), 10)
+	case slog.KindUint64:
+		return strconv.AppendUint(buf, v.Uint64(), 10)
+	case slog.KindFloat64:
+		f := v.Float64()
+		if math.IsNaN(f) || math.IsInf(f, 0) {
+			return appendJSONString(buf, strconv.FormatFloat(f, 'g', -1, 64))
+		}
+		return strconv.AppendFloat(buf, f, 'g', -1, 64)
+	case slog.KindBool:
+		return strconv.AppendBool(buf, v.Bool())
+	case slog.KindDuration:
+		return appendJSONString(buf, v.Duration().String())
+	case slog.KindTime:
+		buf = append(buf, '"')
+		buf = v.Time().AppendFormat(buf, time.RFC3339Nano)
+		return append(buf, '"')
+	}
+
+	switch x := v.Any().(type) {
+	case nil:
+		return append(buf, "null"...)
+	case error:
+		return appendJSONString(buf, x.Error())
+	case json.Marshaler:
+		if b, err := x.MarshalJSON(); err == nil && json.Valid(b) {
+			return append(buf, b...)
+		}
+	case encoding.TextMarshaler:
+		if b, err := x.MarshalText(); err == nil {
+			return appendJSONString(buf, string(b))
+		}
+	case []byte:
+		return appendJSONString(buf, string(x))
+	case fmt.Stringer:
+		return appendJSONString(buf, x.String())
+	}
+	if b, err := json.Marshal(v.Any()); err == nil {
+		return append(buf, b...)
+	}
+	return appendJSONString(buf, fmt.Sprint(v.Any()))
+}
+
+// appendJSONString appends s as a JSON string, replacing invalid UTF-8.
+func appendJSONString(buf []byte, s string) []byte {
+	buf = append(buf, '"')
+	start := 0
+	for i := 0; i < len(s); {
+		c := s[i]
+		if c < utf8.RuneSelf {
+			if c >= ' ' && c != '"' && c != '\\' {
+				i++
+				continue
+			}
+			buf = append(buf, s[start:i]...)
+			switch c {
+			case '"', '\\':
+				buf = append(buf, '\\', c)
+			case '\n':
+				buf = append(buf, `\n`...)
+			case '\r':
+				buf = append(buf, `\r`...)
+			case '\t':
+				buf = append(buf, `\t`...)
+			default:
+				buf = append(buf, `\u00`...)
+				buf = append(buf, hexDigits[c>>4], hexDigits[c&0xf])
+			}
+			i++
+			start = i
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if r == utf8.RuneError && size == 1 {
+			buf = append(buf, s[start:i]...)
+			buf = append(buf, `\ufffd`...)
+			i += size
+			start = i
+			continue
+		}
+		if r == '\u2028' || r == '\u2029' {
+			buf = append(buf, s[start:i]...)
+			buf = append(buf, `\u202`...)
+			buf = append(buf, hexDigits[r&0xf])
+			i += size
+			start = i
+			continue
+		}
+		i += size
+	}
+	buf = append(buf, s[start:]...)
+	return append(buf, '"')
+}
@@ -0,0 +1,45 @@
+package logger
+
+import "log/slog"
+
+// KeyNames renames the built-in keys of the records written by NewLogger,
+// for ingestion pipelines expecting specific ones. Empty fields keep the
+// defaults: time, level, msg and caller.
+type KeyNames struct {
+	Time    string `json:"time,omitempty"`
+	Level   string `json:"level,omitempty"`
+	Message string `json:"message,omitempty"`
+	Source  string `json:"source,omitempty"`
+}
+
+var (
+	// KeyNamesECS are the keys of Elastic Common Schema.
+	KeyNamesECS = KeyNames{Time: "@timestamp", Level: "log.level", Message: "message", Source: "log.origin"}
+	// KeyNamesGCP are the keys Google Cloud Logging reads from JSON payloads.
+	KeyNamesGCP = KeyNames{Time: "time", Level: "severity", Message: "message"}
+	// KeyNamesDatadog are the reserved attributes of Datadog.
+	KeyNamesDatadog = KeyNames{Time: "timestamp", Level: "status", Message: "message", Source: "logger.caller"}
+)
+
+// logger.NewLogger(os.Stdout, logger.WithJSON(true), logger.WithKeyNames(logger.KeyNames{Time: "ts", Message: "message", Level: "severity"}))
+func WithKeyNames(names KeyNames) Option {
+	return func(opts *loggerOptions) {
+		opts.keys = names.withDefaults()
+	}
+}
+
+func (k KeyNames) withDefaults() KeyNames {
+	if k.Time == "" {
+		k.Time = slog.TimeKey
+	}
+	if k.Level == "" {
+		k.Level = slog.LevelKey
+	}
+	if k.Message == "" {
+		k.Message = slog.MessageKey
+	}
+	if k.Source == "" {
+		k.Source = "caller"
+	}
+	return k
+}
@@ -0,0 +1,100 @@
+package logger
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+)
+
+// globalLevel is the level of the handlers built by NewLogger, Builder and
+// WatchConfig without WithLevelVar. They set it to the WithLevel level.
+var globalLevel slog.LevelVar
+
+// WithLevelVar makes the handler follow lv instead of the package level
+// changed by SetLevel.
+func WithLevelVar(lv *slog.LevelVar) Option {
+	return func(opts *loggerOptions) {
+		opts.levelVar = lv
+	}
+}
+
+// SetLevel changes the level of the handlers built by NewLogger, Builder
+// and WatchConfig at runtime, e.g. to debug a running service:
+//
+//	logger.SetLevel("debug")
+func SetLevel(level string) error {
+	name := levelString(level)
+	if name == "" {
+		return fmt.Errorf("logger: unknown level %q", level)
+	}
+	globalLevel.Set(levelOf(name))
+
+	if c := currentConfig.Load(); c != nil {
+		nc := *c
+		nc.Level = name
+		currentConfig.Store(&nc)
+	}
+	return nil
+}
+
+// GetLevel returns the package level set by NewLogger, Builder or SetLevel.
+func GetLevel() string {
+	return levelName(globalLevel.Level())
+}
+
+// levelString returns the level name contained in level, case-insensitively,
+// or "" if there is none.
+func levelString(level string) string {
+	level = strings.ToUpper(level)
+	for _, name := range []string{LevelPanic, LevelFatal, LevelError, LevelWarn, LevelInfo, LevelDebug} {
+		if strings.Contains(level, name) {
+			return name
+		}
+	}
+	return ""
+}
+
+// parseLevel returns the level contained in level, defaulting to INFO.
+func parseLevel(level string) slog.Level {
+	return levelOf(levelString(level))
+}
+
+func levelOf(name string) slog.Level {
+	switch name {
+	case LevelDebug:
+		return slog.LevelDebug
+	case LevelWarn:
+		return slog.LevelWarn
+	case LevelError:
+		return slog.LevelError
+	case LevelFatal:
+		return FatalLevel
+	case LevelPanic:
+		return PanicLevel
+	default:
+		return slog.LevelInfo
+	}
+}
+
+// Leveled is implemented by handlers whose Enabled only compares the level
+// with a minimum, like the handlers of NewLogger, so that wrappers can
+// answer Enabled without asking the whole chain.
+type Leveled interface {
+	Leveler() slog.Leveler
+}
+
+// HandlerLevel returns the minimum level of h if it is Leveled, or nil.
+// Wrappers keep it to short-circuit Enabled:
+//
+//	func (h *Wrapper) Enabled(ctx context.Context, level slog.Level) bool {
+//		if h.level != nil {
+//			return level >= h.level.Level()
+//		}
+//		return h.Handler.Enabled(ctx, level)
+//	}
+func HandlerLevel(h slog.Handler) slog.Leveler {
+	if l, ok := h.(Leveled); ok {
+		return l.Leveler()
+	}
+	return nil
+}
@@ -0,0 +1,101 @@
+//go:build !logger_lite && !logger_nohttp
+
+package logger
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+)
+
+type levelResponse struct {
+	Name  string `json:"name,omitempty"`
+	Level string `json:"level"`
+}
+
+// NewLevelHandler reads the level of lv with GET and changes it with PUT or
+// POST, taking the level from the level query parameter, a JSON body
+// {"level":"debug"} or a plain text body. With a nil lv it changes the
+// package level, followed by the handlers of NewLogger and Builder, through
+// SetLevel, or with a name query parameter the level of the loggers
+// returned by Get, through SetNamedLevel. Like NewControlHandler it only
+// serves loopback clients unless WithControlAuth is given.
+//
+//	mux.Handle("/debug/logger/level", logger.NewLevelHandler(nil, logger.WithControlAuth(logger.ControlBearerAuth(token))))
+//	curl -X PUT 'localhost:8080/debug/logger/level?level=debug'
+//	curl -X PUT 'localhost:8080/debug/logger/level?name=payments.db&level=error'
+func NewLevelHandler(lv *slog.LevelVar, options ...ControlOption) http.Handler {
+	opts := ControlOptions(options...)
+
+	get := func(name string) string {
+		if lv == nil {
+			if name != "" {
+				return GetNamedLevel(name)
+			}
+			return GetLevel()
+		}
+		return levelName(lv.Level())
+	}
+	set := func(name, level string) error {
+		if lv == nil {
+			if name != "" {
+				return SetNamedLevel(name, level)
+			}
+			return SetLevel(level)
+		}
+		s := levelString(level)
+		if s == "" {
+			return fmt.Errorf("logger: unknown level %q", level)
+		}
+		lv.Set(levelOf(s))
+		return nil
+	}
+
+	var h http.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name := r.URL.Query().Get("name")
+		switch r.Method {
+		case http.MethodGet, http.MethodHead:
+		case http.MethodPut, http.MethodPost:
+			level, err := requestLevel(r)
+			if err == nil {
+				err = set(name, level)
+			}
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			slog.Default().Info("log level changed", "level", get(name), "name", name, "remote_addr", r.RemoteAddr)
+		default:
+			w.Header().Set("Allow", "GET, HEAD, PUT, POST")
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-store")
+		_ = json.NewEncoder(w).Encode(levelResponse{Name: name, Level: get(name)})
+	})
+
+	if opts.auth != nil {
+		return opts.auth(h)
+	}
+	return loopbackOnly(h)
+}
+
+func requestLevel(r *http.Request) (string, error) {
+	if level := r.URL.Query().Get("level"); level != "" {
+		return level, nil
+	}
+	body, err := io.ReadAll(io.LimitReader(r.Body, 1<<10))
+	if err != nil {
+		return "", err
+	}
+	var req levelResponse
+	if json.Unmarshal(body, &req) == nil && req.Level != "" {
+		return req.Level, nil
+	}
+	return strings.TrimSpace(string(body)), nil
+}
@@ -0,0 +1,107 @@
+package logger
+
+import (
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// rateLimiter is a token bucket refilled at rate tokens per second.
+type rateLimiter struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func newRateLimiter(rate float64, burst int) *rateLimiter {
+	if burst < 1 {
+		burst = 1
+	}
+	return &rateLimiter{rate: rate, burst: float64(burst), tokens: float64(burst)}
+}
+
+func (l *rateLimiter) allow(now time.Time) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if !l.last.IsZero() {
+		l.tokens += now.Sub(l.last).Seconds() * l.rate
+		if l.tokens > l.burst {
+			l.tokens = l.burst
+		}
+	}
+	l.last = now
+
+	if l.tokens < 1 {
+		return false
+	}
+	l.tokens--
+	return true
+}
+
+// windowQuota limits records and bytes per fixed time window.
+type windowQuota struct {
+	mu      sync.Mutex
+	window  time.Duration
+	records int64
+	bytes   int64
+
+	start    time.Time
+	n        int64
+	size     int64
+	exceeded bool
+}
+
+func newWindowQuota(window time.Duration, records, bytes int64) *windowQuota {
+	if window <= 0 {
+		window = time.Minute
+	}
+	return &windowQuota{window: window, records: records, bytes: bytes}
+}
+
+// allow reports whether a record of size bytes fits into the current window
+// and whether it is the first record rejected in that window.
+func (q *windowQuota) allow(now time.Time, size int64) (ok, first bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if now.Sub(q.start) >= q.window {
+		q.start = now
+		q.n, q.size = 0, 0
+		q.exceeded = false
+	}
+
+	if (q.records > 0 && q.n+1 > q.records) || (q.bytes > 0 && q.size+size > q.bytes) {
+		first = !q.exceeded
+		q.exceeded = true
+		return false, first
+	}
+	q.n++
+	q.size += size
+	return true, false
+}
+
+// recordSize estimates the encoded size of r without encoding it.
+func recordSize(r slog.Record) int64 {
+	n := int64(len(r.Message))
+	r.Attrs(func(a slog.Attr) bool {
+		n += int64(AttrSize(a))
+		return true
+	})
+	return n
+}
+
+// AttrSize approximates the encoded size of a without encoding it, as used
+// to size records by the quotas and the metrics package.
+func AttrSize(a slog.Attr) int {
+	n := len(a.Key) + 2
+	if a.Value.Kind() == slog.KindGroup {
+		for _, ga := range a.Value.Group() {
+			n += AttrSize(ga)
+		}
+		return n
+	}
+	return n + len(a.Value.String())
+}
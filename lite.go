@@ -0,0 +1,19 @@
+//go:build logger_lite
+
+// The logger_lite build tag drops every optional feature listed in
+// features.go, so the core handlers build for WebAssembly and TinyGo
+// targets:
+//
+//	GOOS=wasip1 GOARCH=wasm go build -tags logger_lite
+//	tinygo build -target wasi -tags logger_lite
+
+package logger
+
+import (
+	"os"
+	"path/filepath"
+)
+
+var goModDir = filepath.ToSlash(filepath.Join(os.Getenv("GOPATH"), "pkg", "mod")) + "/"
+
+const liteBuild = true
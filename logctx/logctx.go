@@ -0,0 +1,60 @@
+// Package logctx enriches contexts with values that the handler built by
+// logger.NewLogger adds to every record logged with them:
+//
+//	ctx = logctx.WithRequestID(ctx, r.Header.Get("X-Request-Id"))
+//	ctx = logctx.WithAttrs(ctx, slog.String("user", user))
+//	slog.InfoContext(ctx, "handled") // ... request_id=... user=...
+package logctx
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/isauran/logger"
+)
+
+// WithSource reports s as the source of records logged with ctx.
+func WithSource(ctx context.Context, s *slog.Source) context.Context {
+	return logger.SourceContext(ctx, s)
+}
+
+// WithCaller reports the caller skip frames above the caller of WithCaller
+// as the source of records logged with ctx.
+func WithCaller(ctx context.Context, skip int) context.Context {
+	return logger.ContextWithCaller(ctx, skip+1)
+}
+
+// WithCallerSkip skips n more frames when resolving the source of each
+// record logged with ctx.
+func WithCallerSkip(ctx context.Context, n int) context.Context {
+	return logger.ContextWithCallerSkip(ctx, n)
+}
+
+// WithAttrs adds attrs to those already carried by ctx.
+func WithAttrs(ctx context.Context, attrs ...slog.Attr) context.Context {
+	return logger.ContextWithAttrs(ctx, attrs...)
+}
+
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return logger.ContextWithRequestID(ctx, id)
+}
+
+func WithTraceID(ctx context.Context, traceID, spanID string) context.Context {
+	return logger.ContextWithTrace(ctx, traceID, spanID)
+}
+
+func Source(ctx context.Context) (*slog.Source, bool) {
+	return logger.SourceFromContext(ctx)
+}
+
+func Attrs(ctx context.Context) []slog.Attr {
+	return logger.AttrsFromContext(ctx)
+}
+
+func RequestID(ctx context.Context) string {
+	return logger.RequestIDFromContext(ctx)
+}
+
+func TraceID(ctx context.Context) (traceID, spanID string) {
+	return logger.TraceFromContext(ctx)
+}
@@ -0,0 +1,43 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Breaker is a circuit breaker guarding a sink, e.g. logger.BreakerHandler.
+type Breaker interface {
+	State() string
+	Dropped() uint64
+}
+
+// breakerStates maps the states of a Breaker to breaker_state values.
+var breakerStates = map[string]float64{
+	"closed":    0,
+	"half-open": 1,
+	"open":      2,
+}
+
+// RegisterBreaker exposes the state of b as the breaker_state gauge, 0
+// closed, 1 half-open and 2 open, and the records it dropped as
+// breaker_dropped_records_total, both labeled with sink.
+//
+//	b := logger.NewBreakerHandler(tcpHandler)
+//	metrics.RegisterBreaker("tcp", b)
+func RegisterBreaker(sink string, b Breaker, options ...Option) error {
+	opts := MetricsOptions(options...)
+	labels := prometheus.Labels{"sink": sink}
+	if err := opts.registerer.Register(prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace:   opts.namespace,
+		Name:        "breaker_state",
+		Help:        "Circuit breaker state of the sink: 0 closed, 1 half-open, 2 open.",
+		ConstLabels: labels,
+	}, func() float64 { return breakerStates[b.State()] })); err != nil {
+		return err
+	}
+	return opts.registerer.Register(prometheus.NewCounterFunc(prometheus.CounterOpts{
+		Namespace:   opts.namespace,
+		Name:        "breaker_dropped_records_total",
+		Help:        "Records dropped while the circuit breaker of the sink was open.",
+		ConstLabels: labels,
+	}, func() float64 { return float64(b.Dropped()) }))
+}
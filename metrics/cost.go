@@ -0,0 +1,91 @@
+package metrics
+
+import (
+	"context"
+	"log/slog"
+	"sync/atomic"
+	"time"
+
+	"github.com/isauran/logger"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// recordOverhead approximates the bytes every record spends on time, level
+// and msg keys and delimiters.
+const recordOverhead = 48
+
+// CostHandler estimates the ingestion cost of a sink from the approximate
+// encoded size of the records passed to it.
+type CostHandler struct {
+	slog.Handler
+	core *costCore
+	size int
+}
+
+type costCore struct {
+	pricePerGB float64
+	start      time.Time
+	bytes      atomic.Int64
+}
+
+// h := metrics.NewCostHandler(lokiHandler, "loki", 0.50)
+// exposes logger_sink_bytes_total{sink="loki"} and logger_estimated_daily_cost{sink="loki"}
+//
+// It panics if a metric cannot be registered, as prometheus.MustRegister
+// does; a sink already registered keeps the metrics of its first handler.
+func NewCostHandler(h slog.Handler, sink string, pricePerGB float64, options ...Option) *CostHandler {
+	opts := MetricsOptions(options...)
+
+	c := &costCore{pricePerGB: pricePerGB, start: time.Now()}
+	labels := prometheus.Labels{"sink": sink}
+	register(opts.registerer, prometheus.NewCounterFunc(prometheus.CounterOpts{
+		Namespace:   opts.namespace,
+		Name:        "sink_bytes_total",
+		Help:        "Approximate bytes written to the sink.",
+		ConstLabels: labels,
+	}, func() float64 { return float64(c.bytes.Load()) }))
+	register(opts.registerer, prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace:   opts.namespace,
+		Name:        "estimated_daily_cost",
+		Help:        "Ingestion cost per day extrapolated from the bytes written so far.",
+		ConstLabels: labels,
+	}, c.dailyCost))
+
+	return &CostHandler{Handler: h, core: c}
+}
+
+func (c *costCore) dailyCost() float64 {
+	elapsed := time.Since(c.start).Seconds()
+	if elapsed <= 0 {
+		return 0
+	}
+	perDay := float64(c.bytes.Load()) / elapsed * (24 * 60 * 60)
+	return perDay / 1e9 * c.pricePerGB
+}
+
+// EstimatedDailyCost returns the value of the estimated_daily_cost gauge.
+func (h *CostHandler) EstimatedDailyCost() float64 {
+	return h.core.dailyCost()
+}
+
+func (h *CostHandler) Handle(ctx context.Context, r slog.Record) error {
+	size := recordOverhead + h.size + len(r.Message)
+	r.Attrs(func(a slog.Attr) bool {
+		size += logger.AttrSize(a)
+		return true
+	})
+	h.core.bytes.Add(int64(size))
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h *CostHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	size := h.size
+	for _, a := range attrs {
+		size += logger.AttrSize(a)
+	}
+	return &CostHandler{Handler: h.Handler.WithAttrs(attrs), core: h.core, size: size}
+}
+
+func (h *CostHandler) WithGroup(name string) slog.Handler {
+	return &CostHandler{Handler: h.Handler.WithGroup(name), core: h.core, size: h.size + len(name)}
+}
@@ -0,0 +1,19 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// RegisterDegradeStep exposes the rung of a degradation ladder as the
+// degrade_step gauge, 0 meaning normal operation.
+//
+//	d := logger.NewDegradeHandler(h, policy)
+//	metrics.RegisterDegradeStep(func() float64 { return float64(d.Step()) })
+func RegisterDegradeStep(step func() float64, options ...Option) error {
+	opts := MetricsOptions(options...)
+	return opts.registerer.Register(prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace: opts.namespace,
+		Name:      "degrade_step",
+		Help:      "Current rung of the log degradation ladder, 0 when not degraded.",
+	}, step))
+}
@@ -0,0 +1,268 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+
+	"github.com/isauran/logger"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const OverflowLabelValue string = "other"
+
+type Option func(*metricsOptions)
+
+type metricsOptions struct {
+	registerer prometheus.Registerer
+	namespace  string
+	labels     []string
+	maxValues  int
+	histograms bool
+}
+
+func WithRegisterer(r prometheus.Registerer) Option {
+	return func(opts *metricsOptions) {
+		opts.registerer = r
+	}
+}
+
+func WithNamespace(namespace string) Option {
+	return func(opts *metricsOptions) {
+		opts.namespace = namespace
+	}
+}
+
+// WithLabels adds up to two record attrs (e.g. "component") as metric labels.
+// Extra keys are ignored.
+func WithLabels(keys ...string) Option {
+	return func(opts *metricsOptions) {
+		if len(keys) > 2 {
+			keys = keys[:2]
+		}
+		opts.labels = keys
+	}
+}
+
+// WithMaxLabelValues caps the distinct values tracked per label; values seen
+// after the cap are counted as OverflowLabelValue.
+func WithMaxLabelValues(n int) Option {
+	return func(opts *metricsOptions) {
+		opts.maxValues = n
+	}
+}
+
+// WithRecordHistograms adds per-level histograms of the approximate encoded
+// record size and of the number of attrs, to find code paths producing
+// pathologically large records.
+func WithRecordHistograms() Option {
+	return func(opts *metricsOptions) {
+		opts.histograms = true
+	}
+}
+
+func MetricsOptions(options ...Option) *metricsOptions {
+	opts := &metricsOptions{
+		registerer: prometheus.DefaultRegisterer,
+		namespace:  "logger",
+		maxValues:  50,
+	}
+	for _, opt := range options {
+		opt(opts)
+	}
+	return opts
+}
+
+// MetricsHandler counts records per level, and per configured attr labels,
+// before passing them to the wrapped handler.
+type MetricsHandler struct {
+	slog.Handler
+	core    *core
+	level   slog.Leveler
+	values  []string
+	grouped bool
+	nattrs  int
+	size    int
+}
+
+type core struct {
+	labels  []string
+	guards  []*labelGuard
+	records *prometheus.CounterVec
+	errors  *prometheus.CounterVec
+	sizes   *prometheus.HistogramVec
+	attrs   *prometheus.HistogramVec
+}
+
+// h := metrics.New(slog.NewJSONHandler(os.Stdout, nil), metrics.WithLabels("component"))
+//
+// Metrics already registered by another handler are shared with it; New
+// panics if a metric cannot be registered, as prometheus.MustRegister does.
+func New(h slog.Handler, options ...Option) *MetricsHandler {
+	opts := MetricsOptions(options...)
+
+	labels := append([]string{"level"}, opts.labels...)
+	c := &core{
+		labels: opts.labels,
+		records: register(opts.registerer, prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: opts.namespace,
+			Name:      "records_total",
+			Help:      "Number of log records handled.",
+		}, labels)),
+		errors: register(opts.registerer, prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: opts.namespace,
+			Name:      "handle_errors_total",
+			Help:      "Number of log records the wrapped handler failed to handle.",
+		}, []string{"level"})),
+	}
+	if opts.histograms {
+		c.sizes = register(opts.registerer, prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: opts.namespace,
+			Name:      "record_size_bytes",
+			Help:      "Approximate encoded size of log records.",
+			Buckets:   prometheus.ExponentialBuckets(64, 4, 8),
+		}, []string{"level"}))
+		c.attrs = register(opts.registerer, prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: opts.namespace,
+			Name:      "record_attrs",
+			Help:      "Number of attrs in log records, including attrs added with WithAttrs.",
+			Buckets:   prometheus.ExponentialBuckets(1, 2, 8),
+		}, []string{"level"}))
+	}
+	for range opts.labels {
+		c.guards = append(c.guards, &labelGuard{max: opts.maxValues, seen: make(map[string]struct{})})
+	}
+
+	return &MetricsHandler{Handler: h, core: c, level: logger.HandlerLevel(h), values: make([]string, len(opts.labels))}
+}
+
+// Enabled answers from the level of the wrapped handler, when it is
+// logger.Leveled.
+func (h *MetricsHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	if h.level != nil {
+		return level >= h.level.Level()
+	}
+	return h.Handler.Enabled(ctx, level)
+}
+
+// register registers c with r, returning the collector already registered
+// in its place if any. It panics on other errors.
+func register[T prometheus.Collector](r prometheus.Registerer, c T) T {
+	if err := r.Register(c); err != nil {
+		var are prometheus.AlreadyRegisteredError
+		if errors.As(err, &are) {
+			if existing, ok := are.ExistingCollector.(T); ok {
+				return existing
+			}
+		}
+		panic(err)
+	}
+	return c
+}
+
+func (h *MetricsHandler) Handle(ctx context.Context, r slog.Record) error {
+	level := r.Level.String()
+
+	values := h.values
+	if len(h.core.labels) > 0 {
+		values = make([]string, len(h.values))
+		copy(values, h.values)
+		r.Attrs(func(a slog.Attr) bool {
+			for i, key := range h.core.labels {
+				if a.Key == key {
+					values[i] = a.Value.String()
+				}
+			}
+			return true
+		})
+		for i, v := range values {
+			values[i] = h.core.guards[i].value(v)
+		}
+	}
+
+	h.core.records.WithLabelValues(append([]string{level}, values...)...).Inc()
+	if h.core.sizes != nil {
+		size := h.size + len(r.Message)
+		r.Attrs(func(a slog.Attr) bool {
+			size += logger.AttrSize(a)
+			return true
+		})
+		h.core.sizes.WithLabelValues(level).Observe(float64(size))
+		h.core.attrs.WithLabelValues(level).Observe(float64(h.nattrs + r.NumAttrs()))
+	}
+
+	err := h.Handler.Handle(ctx, r)
+	if err != nil {
+		h.core.errors.WithLabelValues(level).Inc()
+	}
+	return err
+}
+
+func (h *MetricsHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	values := make([]string, len(h.values))
+	copy(values, h.values)
+	if !h.grouped {
+		for _, a := range attrs {
+			for i, key := range h.core.labels {
+				if a.Key == key {
+					values[i] = a.Value.String()
+				}
+			}
+		}
+	}
+	size := h.size
+	for _, a := range attrs {
+		size += logger.AttrSize(a)
+	}
+	return &MetricsHandler{
+		Handler: h.Handler.WithAttrs(attrs),
+		core:    h.core,
+		level:   h.level,
+		values:  values,
+		grouped: h.grouped,
+		nattrs:  h.nattrs + len(attrs),
+		size:    size,
+	}
+}
+
+func (h *MetricsHandler) WithGroup(name string) slog.Handler {
+	return &MetricsHandler{
+		Handler: h.Handler.WithGroup(name),
+		core:    h.core,
+		level:   h.level,
+		values:  h.values,
+		grouped: true,
+		nattrs:  h.nattrs,
+		size:    h.size + len(name),
+	}
+}
+
+// labelGuard bounds the cardinality of a single label.
+type labelGuard struct {
+	max int
+
+	mu   sync.RWMutex
+	seen map[string]struct{}
+}
+
+func (g *labelGuard) value(v string) string {
+	g.mu.RLock()
+	_, ok := g.seen[v]
+	g.mu.RUnlock()
+	if ok {
+		return v
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if _, ok := g.seen[v]; ok {
+		return v
+	}
+	if g.max > 0 && len(g.seen) >= g.max {
+		return OverflowLabelValue
+	}
+	g.seen[v] = struct{}{}
+	return v
+}
@@ -0,0 +1,36 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Retrier retries records a sink failed on, e.g. logger.RetryHandler.
+type Retrier interface {
+	Retried() uint64
+	Dropped() uint64
+}
+
+// RegisterRetry exposes the retries of r as retry_attempts_total and the
+// records it gave up on as retry_dropped_records_total, both labeled with
+// sink.
+//
+//	r := logger.NewRetryHandler(lokiHandler)
+//	metrics.RegisterRetry("loki", r)
+func RegisterRetry(sink string, r Retrier, options ...Option) error {
+	opts := MetricsOptions(options...)
+	labels := prometheus.Labels{"sink": sink}
+	if err := opts.registerer.Register(prometheus.NewCounterFunc(prometheus.CounterOpts{
+		Namespace:   opts.namespace,
+		Name:        "retry_attempts_total",
+		Help:        "Retried records and flushes of the sink.",
+		ConstLabels: labels,
+	}, func() float64 { return float64(r.Retried()) })); err != nil {
+		return err
+	}
+	return opts.registerer.Register(prometheus.NewCounterFunc(prometheus.CounterOpts{
+		Namespace:   opts.namespace,
+		Name:        "retry_dropped_records_total",
+		Help:        "Records of the sink dropped after running out of retries.",
+		ConstLabels: labels,
+	}, func() float64 { return float64(r.Dropped()) }))
+}
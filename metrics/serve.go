@@ -0,0 +1,46 @@
+package metrics
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Handler serves the metrics of the registerer set with WithRegisterer, the
+// default registry otherwise, in the Prometheus exposition format.
+//
+//	reg := prometheus.NewRegistry()
+//	h := metrics.New(h, metrics.WithRegisterer(reg))
+//	mux.Handle("/metrics", metrics.Handler(metrics.WithRegisterer(reg)))
+func Handler(options ...Option) http.Handler {
+	opts := MetricsOptions(options...)
+
+	gatherer := prometheus.DefaultGatherer
+	if g, ok := opts.registerer.(prometheus.Gatherer); ok {
+		gatherer = g
+	}
+	return promhttp.HandlerFor(gatherer, promhttp.HandlerOpts{
+		ErrorHandling:     promhttp.ContinueOnError,
+		Timeout:           10 * time.Second,
+		EnableOpenMetrics: true,
+	})
+}
+
+// Serve serves Handler at /metrics on addr, for small services without an
+// HTTP server of their own. It blocks like http.ListenAndServe.
+//
+//	go func() { _ = metrics.Serve(":9090") }()
+func Serve(addr string, options ...Option) error {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", Handler(options...))
+
+	srv := &http.Server{
+		Addr:              addr,
+		Handler:           mux,
+		ReadHeaderTimeout: 5 * time.Second,
+		WriteTimeout:      15 * time.Second,
+	}
+	return srv.ListenAndServe()
+}
@@ -0,0 +1,20 @@
+package logger
+
+import "log/slog"
+
+// Middleware wraps a handler, e.g. in a SamplingHandler or RedactHandler.
+type Middleware func(slog.Handler) slog.Handler
+
+// Chain wraps h in mws, the first outermost, so records pass through mws
+// in the order they are listed before reaching h.
+//
+//	h := logger.Chain(base,
+//		func(h slog.Handler) slog.Handler { return logger.NewSamplingHandler(h) },
+//		func(h slog.Handler) slog.Handler { return logger.NewRecentErrorsHandler(h, 100, 10*time.Minute) },
+//	)
+func Chain(h slog.Handler, mws ...Middleware) slog.Handler {
+	for i := len(mws) - 1; i >= 0; i-- {
+		h = mws[i](h)
+	}
+	return h
+}
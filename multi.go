@@ -0,0 +1,253 @@
+package logger
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"sync"
+)
+
+const (
+	RouteToKey   string = "logger.to"
+	RouteSkipKey string = "logger.skip"
+)
+
+// To routes a record only to the named sinks of a MultiHandler.
+// slog.Info("payment captured", logger.To("audit"))
+func To(sinks ...string) slog.Attr {
+	return slog.Any(RouteToKey, sinks)
+}
+
+// Skip excludes the named sinks of a MultiHandler for a record.
+// slog.Debug("cache dump", logger.Skip("console"))
+func Skip(sinks ...string) slog.Attr {
+	return slog.Any(RouteSkipKey, sinks)
+}
+
+// Sink is a named MultiHandler destination. An Explicit sink only receives
+// records routed to it with To.
+type Sink struct {
+	Name     string
+	Handler  slog.Handler
+	Explicit bool
+}
+
+// MultiHandler fans records out to several sinks, honoring To and Skip hints
+// given on the record or with WithAttrs. Hint attrs are not forwarded. Sinks
+// can be replaced at runtime with SwapSink.
+type MultiHandler struct {
+	core *multiCore
+	to   []string
+	skip []string
+	wrap []func(slog.Handler) slog.Handler
+
+	mu      sync.Mutex
+	derived []derivedSink
+}
+
+type multiCore struct {
+	mu    sync.RWMutex
+	slots []*sinkSlot
+}
+
+// sinkSlot is one generation of a sink; SwapSink replaces it and waits
+// for the records still being handled by the old one.
+type sinkSlot struct {
+	Sink
+	gen    uint64
+	active sync.WaitGroup
+}
+
+type derivedSink struct {
+	gen     uint64
+	handler slog.Handler
+}
+
+// logger.NewMultiHandler(logger.Sink{Name: "console", Handler: console}, logger.Sink{Name: "audit", Handler: audit, Explicit: true})
+func NewMultiHandler(sinks ...Sink) *MultiHandler {
+	c := &multiCore{}
+	for _, s := range sinks {
+		c.slots = append(c.slots, &sinkSlot{Sink: s, gen: 1})
+	}
+	return &MultiHandler{core: c}
+}
+
+// SwapSink atomically replaces the handler of the sink named name, for h
+// and all handlers derived from it, e.g. to migrate to another backend
+// without downtime. It waits until the old handler finished the records it
+// was handling and then closes it if it is an io.Closer.
+func (h *MultiHandler) SwapSink(name string, handler slog.Handler) error {
+	c := h.core
+	c.mu.Lock()
+	var old *sinkSlot
+	for i, slot := range c.slots {
+		if slot.Name == name {
+			old = slot
+			c.slots[i] = &sinkSlot{
+				Sink: Sink{Name: name, Handler: handler, Explicit: slot.Explicit},
+				gen:  slot.gen + 1,
+			}
+			break
+		}
+	}
+	c.mu.Unlock()
+
+	if old == nil {
+		return fmt.Errorf("logger: no sink %q", name)
+	}
+	old.active.Wait()
+	if closer, ok := old.Handler.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+// acquire returns the current sink slots, marked active until release.
+func (c *multiCore) acquire() []*sinkSlot {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	slots := make([]*sinkSlot, len(c.slots))
+	copy(slots, c.slots)
+	for _, slot := range slots {
+		slot.active.Add(1)
+	}
+	return slots
+}
+
+func release(slots []*sinkSlot) {
+	for _, slot := range slots {
+		slot.active.Done()
+	}
+}
+
+// handler returns the i-th sink handler with h's attrs and groups applied,
+// rebuilding it when the sink was swapped.
+func (h *MultiHandler) handler(i int, slot *sinkSlot) slog.Handler {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if len(h.derived) <= i {
+		h.derived = append(h.derived, make([]derivedSink, i+1-len(h.derived))...)
+	}
+	if d := h.derived[i]; d.gen == slot.gen {
+		return d.handler
+	}
+	sh := slot.Handler
+	for _, w := range h.wrap {
+		sh = w(sh)
+	}
+	h.derived[i] = derivedSink{gen: slot.gen, handler: sh}
+	return sh
+}
+
+func (h *MultiHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	slots := h.core.acquire()
+	defer release(slots)
+
+	for i, slot := range slots {
+		if h.handler(i, slot).Enabled(ctx, level) {
+			return true
+		}
+	}
+	return false
+}
+
+func (h *MultiHandler) Handle(ctx context.Context, r slog.Record) error {
+	to, skip := h.to, h.skip
+	hinted := false
+	r.Attrs(func(a slog.Attr) bool {
+		switch a.Key {
+		case RouteToKey:
+			to = append(to[:len(to):len(to)], routeNames(a)...)
+			hinted = true
+		case RouteSkipKey:
+			skip = append(skip[:len(skip):len(skip)], routeNames(a)...)
+			hinted = true
+		}
+		return true
+	})
+
+	if hinted {
+		nr := slog.NewRecord(r.Time, r.Level, r.Message, r.PC)
+		r.Attrs(func(a slog.Attr) bool {
+			if a.Key != RouteToKey && a.Key != RouteSkipKey {
+				nr.AddAttrs(a)
+			}
+			return true
+		})
+		r = nr
+	}
+
+	slots := h.core.acquire()
+	defer release(slots)
+
+	var errs []error
+	for i, slot := range slots {
+		if !routed(slot.Sink, to, skip) {
+			continue
+		}
+		sh := h.handler(i, slot)
+		if !sh.Enabled(ctx, r.Level) {
+			continue
+		}
+		if err := sh.Handle(ctx, r.Clone()); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (h *MultiHandler) derive(to, skip []string, w func(slog.Handler) slog.Handler) *MultiHandler {
+	wrap := append(h.wrap[:len(h.wrap):len(h.wrap)], w)
+	return &MultiHandler{core: h.core, to: to, skip: skip, wrap: wrap}
+}
+
+func (h *MultiHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	to, skip := h.to, h.skip
+	rest := make([]slog.Attr, 0, len(attrs))
+	for _, a := range attrs {
+		switch a.Key {
+		case RouteToKey:
+			to = append(to[:len(to):len(to)], routeNames(a)...)
+		case RouteSkipKey:
+			skip = append(skip[:len(skip):len(skip)], routeNames(a)...)
+		default:
+			rest = append(rest, a)
+		}
+	}
+	return h.derive(to, skip, func(sh slog.Handler) slog.Handler { return sh.WithAttrs(rest) })
+}
+
+func (h *MultiHandler) WithGroup(name string) slog.Handler {
+	return h.derive(h.to, h.skip, func(sh slog.Handler) slog.Handler { return sh.WithGroup(name) })
+}
+
+func routeNames(a slog.Attr) []string {
+	switch v := a.Value.Any().(type) {
+	case []string:
+		return v
+	case string:
+		return []string{v}
+	}
+	return nil
+}
+
+func routed(s Sink, to, skip []string) bool {
+	for _, name := range skip {
+		if name == s.Name {
+			return false
+		}
+	}
+	if len(to) == 0 {
+		return !s.Explicit
+	}
+	for _, name := range to {
+		if name == s.Name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,198 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// LoggerNameKey is the key of the name attr of loggers returned by Get.
+const LoggerNameKey string = "logger"
+
+// namedRegistry holds the loggers returned by Get and the level overrides
+// set by SetNamedLevel. Enabled reads the overrides without locking;
+// changes copy the map and swap it in.
+var namedRegistry struct {
+	mu      sync.Mutex
+	loggers map[string]*slog.Logger
+	levels  atomic.Pointer[map[string]slog.Level]
+}
+
+// Get returns the logger named name, logging through the current default
+// logger with a logger=name attr, so loggers got before NewLogger or
+// slog.SetDefault use the handler set later. Names are dot-separated paths: the level set by
+// SetNamedLevel for "payments" also applies to "payments.db" unless it has
+// its own. Without an override the default logger's level applies.
+//
+//	log := logger.Get("payments.db")
+//	logger.SetNamedLevel("payments", "error")
+func Get(name string) *slog.Logger {
+	namedRegistry.mu.Lock()
+	defer namedRegistry.mu.Unlock()
+
+	if l, ok := namedRegistry.loggers[name]; ok {
+		return l
+	}
+	if namedRegistry.loggers == nil {
+		namedRegistry.loggers = make(map[string]*slog.Logger)
+	}
+	l := slog.New(&namedHandler{name: name, bound: &atomic.Pointer[namedBound]{}})
+	namedRegistry.loggers[name] = l
+	return l
+}
+
+// SetNamedLevel overrides the level of the loggers named name and below.
+// An empty level removes the override.
+func SetNamedLevel(name, level string) error {
+	var lvl slog.Level
+	if level != "" {
+		s := levelString(level)
+		if s == "" {
+			return fmt.Errorf("logger: unknown level %q", level)
+		}
+		lvl = levelOf(s)
+	}
+
+	namedRegistry.mu.Lock()
+	defer namedRegistry.mu.Unlock()
+
+	levels := make(map[string]slog.Level)
+	if cur := namedRegistry.levels.Load(); cur != nil {
+		for k, v := range *cur {
+			levels[k] = v
+		}
+	}
+	if level == "" {
+		delete(levels, name)
+	} else {
+		levels[name] = lvl
+	}
+	namedRegistry.levels.Store(&levels)
+	return nil
+}
+
+// GetNamedLevel returns the level of the loggers named name: the closest
+// override set by SetNamedLevel, or the level of the default logger.
+func GetNamedLevel(name string) string {
+	if level, ok := namedLevel(name); ok {
+		return levelName(level)
+	}
+	return levelName(enabledLevel(slog.Default().Handler()))
+}
+
+// enabledLevel returns the lowest level h is enabled for.
+func enabledLevel(h slog.Handler) slog.Level {
+	if l := HandlerLevel(h); l != nil {
+		return l.Level()
+	}
+	for _, level := range []slog.Level{slog.LevelDebug, slog.LevelInfo, slog.LevelWarn, slog.LevelError, FatalLevel} {
+		if h.Enabled(context.Background(), level) {
+			return level
+		}
+	}
+	return PanicLevel
+}
+
+// NamedLevels returns the overrides set by SetNamedLevel.
+func NamedLevels() map[string]string {
+	out := make(map[string]string)
+	if cur := namedRegistry.levels.Load(); cur != nil {
+		for k, v := range *cur {
+			out[k] = levelName(v)
+		}
+	}
+	return out
+}
+
+// namedLevel returns the override of name or of its closest parent.
+func namedLevel(name string) (slog.Level, bool) {
+	cur := namedRegistry.levels.Load()
+	if cur == nil || len(*cur) == 0 {
+		return 0, false
+	}
+	for {
+		if level, ok := (*cur)[name]; ok {
+			return level, true
+		}
+		i := strings.LastIndexByte(name, '.')
+		if i < 0 {
+			return 0, false
+		}
+		name = name[:i]
+	}
+}
+
+// namedHandler logs through the handler of the default logger, rebinding
+// when it changes.
+type namedHandler struct {
+	name  string
+	ops   []namedOp
+	bound *atomic.Pointer[namedBound]
+}
+
+// namedOp is a WithAttrs or WithGroup call, replayed on the handler of a
+// new default logger.
+type namedOp struct {
+	attrs []slog.Attr
+	group string
+}
+
+// namedBound is the handler built for the default logger def.
+type namedBound struct {
+	def     *slog.Logger
+	handler slog.Handler
+}
+
+// handler returns the handler built for the current default logger.
+func (h *namedHandler) handler() slog.Handler {
+	def := slog.Default()
+	if b := h.bound.Load(); b != nil && b.def == def {
+		return b.handler
+	}
+	handler := def.Handler().WithAttrs([]slog.Attr{slog.String(LoggerNameKey, h.name)})
+	for _, op := range h.ops {
+		if op.group != "" {
+			handler = handler.WithGroup(op.group)
+		} else {
+			handler = handler.WithAttrs(op.attrs)
+		}
+	}
+	h.bound.Store(&namedBound{def: def, handler: handler})
+	return handler
+}
+
+func (h *namedHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	if min, ok := namedLevel(h.name); ok {
+		return level >= min
+	}
+	return h.handler().Enabled(ctx, level)
+}
+
+func (h *namedHandler) Handle(ctx context.Context, r slog.Record) error {
+	return h.handler().Handle(ctx, r)
+}
+
+func (h *namedHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+	return h.with(namedOp{attrs: attrs})
+}
+
+func (h *namedHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	return h.with(namedOp{group: name})
+}
+
+func (h *namedHandler) with(op namedOp) *namedHandler {
+	return &namedHandler{
+		name:  h.name,
+		ops:   append(h.ops[:len(h.ops):len(h.ops)], op),
+		bound: &atomic.Pointer[namedBound]{},
+	}
+}
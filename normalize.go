@@ -0,0 +1,158 @@
+package logger
+
+import (
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Canonical forms of TransformNormalize, set in Transform.Unit.
+const (
+	NormalizeMillis  string = "ms"
+	NormalizeBytes   string = "bytes"
+	NormalizeRFC3339 string = "rfc3339"
+	NormalizeBool    string = "bool"
+)
+
+// normalizeTimeLayouts are the string layouts NormalizeRFC3339 accepts.
+var normalizeTimeLayouts = []string{
+	time.RFC3339Nano,
+	time.RFC1123Z,
+	time.RFC1123,
+	time.DateTime,
+	time.DateOnly,
+}
+
+func (t Transform) validateNormalize() error {
+	switch t.Unit {
+	case NormalizeMillis:
+		if _, ok := transformUnits["duration"][t.fromOr("ns")]; !ok {
+			return fmt.Errorf("transform %q %q: %q is not a duration unit", t.Op, t.Key, t.From)
+		}
+	case NormalizeBytes:
+		if _, ok := transformUnits["size"][t.fromOr("b")]; !ok {
+			return fmt.Errorf("transform %q %q: %q is not a size unit", t.Op, t.Key, t.From)
+		}
+	case NormalizeRFC3339, NormalizeBool:
+	default:
+		return fmt.Errorf("transform %q %q: unknown canonical form %q", t.Op, t.Key, t.Unit)
+	}
+	return nil
+}
+
+func (t Transform) fromOr(unit string) string {
+	if t.From == "" {
+		return unit
+	}
+	return strings.ToLower(t.From)
+}
+
+// normalize converts a to the canonical form t.Unit, leaving it unchanged
+// when its value cannot be interpreted. Numbers are taken to be in t.From:
+// nanoseconds, bytes and Unix seconds by default.
+func (t Transform) normalize(a slog.Attr) slog.Attr {
+	v := a.Value.Resolve()
+	switch t.Unit {
+	case NormalizeMillis:
+		if d, ok := normalizeDuration(v, transformUnits["duration"][t.fromOr("ns")]); ok {
+			return slog.Float64(a.Key, float64(d)/float64(time.Millisecond))
+		}
+	case NormalizeBytes:
+		if n, ok := normalizeSize(v, transformUnits["size"][t.fromOr("b")]); ok {
+			return slog.Int64(a.Key, n)
+		}
+	case NormalizeRFC3339:
+		if tm, ok := normalizeTime(v); ok {
+			return slog.String(a.Key, tm.Format(time.RFC3339))
+		}
+	case NormalizeBool:
+		if b, ok := normalizeBool(v); ok {
+			return slog.Bool(a.Key, b)
+		}
+	}
+	return a
+}
+
+func normalizeNumber(v slog.Value) (float64, bool) {
+	switch v.Kind() {
+	case slog.KindInt64:
+		return float64(v.Int64()), true
+	case slog.KindUint64:
+		return float64(v.Uint64()), true
+	case slog.KindFloat64:
+		return v.Float64(), true
+	case slog.KindString:
+		n, err := strconv.ParseFloat(strings.TrimSpace(v.String()), 64)
+		return n, err == nil
+	}
+	return 0, false
+}
+
+func normalizeDuration(v slog.Value, unit float64) (time.Duration, bool) {
+	if v.Kind() == slog.KindDuration {
+		return v.Duration(), true
+	}
+	if v.Kind() == slog.KindString {
+		if d, err := time.ParseDuration(strings.TrimSpace(v.String())); err == nil {
+			return d, true
+		}
+	}
+	n, ok := normalizeNumber(v)
+	return time.Duration(n * unit), ok
+}
+
+func normalizeSize(v slog.Value, unit float64) (int64, bool) {
+	if v.Kind() == slog.KindString {
+		s := strings.ToLower(strings.TrimSpace(v.String()))
+		for _, suffix := range []string{"gb", "mb", "kb", "b"} {
+			if num, ok := strings.CutSuffix(s, suffix); ok {
+				n, err := strconv.ParseFloat(strings.TrimSpace(num), 64)
+				if err != nil {
+					return 0, false
+				}
+				return int64(n * transformUnits["size"][suffix]), true
+			}
+		}
+	}
+	n, ok := normalizeNumber(v)
+	return int64(n * unit), ok
+}
+
+func normalizeTime(v slog.Value) (time.Time, bool) {
+	switch v.Kind() {
+	case slog.KindTime:
+		return v.Time(), true
+	case slog.KindInt64:
+		return time.Unix(v.Int64(), 0), true
+	case slog.KindString:
+		s := strings.TrimSpace(v.String())
+		for _, layout := range normalizeTimeLayouts {
+			if tm, err := time.Parse(layout, s); err == nil {
+				return tm, true
+			}
+		}
+		if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+			return time.Unix(n, 0), true
+		}
+	}
+	return time.Time{}, false
+}
+
+func normalizeBool(v slog.Value) (bool, bool) {
+	switch v.Kind() {
+	case slog.KindBool:
+		return v.Bool(), true
+	case slog.KindInt64:
+		return v.Int64() != 0, true
+	case slog.KindString:
+		switch strings.ToLower(strings.TrimSpace(v.String())) {
+		case "true", "1", "yes", "on":
+			return true, true
+		case "false", "0", "no", "off", "":
+			return false, true
+		}
+	}
+	return false, false
+}
@@ -0,0 +1,138 @@
+package otelmetrics
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/isauran/logger"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+const instrumentationName string = "github.com/isauran/logger"
+
+// OTelHandler reports logger internals as OpenTelemetry metrics:
+// logger.records (per level), logger.handle.duration, logger.dropped and,
+// when the wrapped handler is a Queue, logger.queue.depth.
+type OTelHandler struct {
+	slog.Handler
+	inst *instruments
+}
+
+type instruments struct {
+	records  metric.Int64Counter
+	duration metric.Float64Histogram
+	dropped  metric.Int64Counter
+	levels   map[slog.Level]metric.MeasurementOption
+}
+
+// Queue is a handler queueing records, e.g. logger.RetryHandler or
+// logger.EncodePoolHandler.
+type Queue interface {
+	QueueDepth() int
+}
+
+// h, err := otelmetrics.New(slog.NewJSONHandler(os.Stdout, nil), otel.GetMeterProvider())
+func New(h slog.Handler, provider metric.MeterProvider) (*OTelHandler, error) {
+	meter := provider.Meter(instrumentationName)
+
+	records, err := meter.Int64Counter("logger.records",
+		metric.WithDescription("Number of log records handled."))
+	if err != nil {
+		return nil, err
+	}
+	duration, err := meter.Float64Histogram("logger.handle.duration",
+		metric.WithDescription("Time spent in the wrapped handler."),
+		metric.WithUnit("ms"))
+	if err != nil {
+		return nil, err
+	}
+	dropped, err := meter.Int64Counter("logger.dropped",
+		metric.WithDescription("Number of log records the wrapped handler failed to handle."))
+	if err != nil {
+		return nil, err
+	}
+
+	inst := &instruments{
+		records:  records,
+		duration: duration,
+		dropped:  dropped,
+		levels:   make(map[slog.Level]metric.MeasurementOption),
+	}
+	for _, l := range []slog.Level{slog.LevelDebug, slog.LevelInfo, slog.LevelWarn, slog.LevelError} {
+		inst.levels[l] = metric.WithAttributeSet(attribute.NewSet(attribute.String("level", l.String())))
+	}
+	if q, ok := h.(Queue); ok {
+		if _, err := RegisterQueue(provider, "handler", q); err != nil {
+			return nil, err
+		}
+	}
+
+	return &OTelHandler{Handler: h, inst: inst}, nil
+}
+
+// Middleware wraps the handler of a logger.Builder in an OTelHandler.
+// Errors creating the instruments go to otel.Handle, leaving the handler
+// unwrapped.
+//
+//	logger.NewBuilder().WithMiddleware(otelmetrics.Middleware(otel.GetMeterProvider())).Build()
+func Middleware(provider metric.MeterProvider) logger.Middleware {
+	return func(h slog.Handler) slog.Handler {
+		oh, err := New(h, provider)
+		if err != nil {
+			otel.Handle(err)
+			return h
+		}
+		return oh
+	}
+}
+
+// RegisterQueue reports the depth of q as logger.queue.depth, labeled with
+// queue=name, e.g. for a queue deeper in the handler chain:
+//
+//	r := logger.NewRetryHandler(lokiHandler)
+//	reg, err := otelmetrics.RegisterQueue(otel.GetMeterProvider(), "loki", r)
+func RegisterQueue(provider metric.MeterProvider, name string, q Queue) (metric.Registration, error) {
+	meter := provider.Meter(instrumentationName)
+	depth, err := meter.Int64ObservableGauge("logger.queue.depth",
+		metric.WithDescription("Number of log records waiting in a queue."))
+	if err != nil {
+		return nil, err
+	}
+	set := metric.WithAttributeSet(attribute.NewSet(attribute.String("queue", name)))
+	return meter.RegisterCallback(func(_ context.Context, o metric.Observer) error {
+		o.ObserveInt64(depth, int64(q.QueueDepth()), set)
+		return nil
+	}, depth)
+}
+
+func (i *instruments) level(l slog.Level) metric.MeasurementOption {
+	if opt, ok := i.levels[l]; ok {
+		return opt
+	}
+	return metric.WithAttributes(attribute.String("level", l.String()))
+}
+
+func (h *OTelHandler) Handle(ctx context.Context, r slog.Record) error {
+	level := h.inst.level(r.Level)
+	begin := time.Now()
+
+	err := h.Handler.Handle(ctx, r)
+
+	h.inst.duration.Record(ctx, float64(time.Since(begin).Nanoseconds())/1e6, level)
+	h.inst.records.Add(ctx, 1, level)
+	if err != nil {
+		h.inst.dropped.Add(ctx, 1, level)
+	}
+	return err
+}
+
+func (h *OTelHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &OTelHandler{Handler: h.Handler.WithAttrs(attrs), inst: h.inst}
+}
+
+func (h *OTelHandler) WithGroup(name string) slog.Handler {
+	return &OTelHandler{Handler: h.Handler.WithGroup(name), inst: h.inst}
+}
@@ -0,0 +1,125 @@
+package oteltrace
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+
+	"github.com/isauran/logger"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	TraceIDKey    string = "trace_id"
+	SpanIDKey     string = "span_id"
+	TraceFlagsKey string = "trace_flags"
+)
+
+// ErrorHandler links records at or above ERROR to the span active in the
+// record context: the error is recorded on the span with a stack trace, the
+// span status is set, and the span ids are added to the record.
+type ErrorHandler struct {
+	slog.Handler
+	level   slog.Leveler
+	enabled slog.Leveler
+}
+
+// h := oteltrace.NewErrorHandler(slog.NewJSONHandler(os.Stdout, nil), slog.LevelError)
+// slog.New(h).ErrorContext(ctx, "charge failed", "err", err)
+func NewErrorHandler(h slog.Handler, level slog.Leveler) *ErrorHandler {
+	if level == nil {
+		level = slog.LevelError
+	}
+	return &ErrorHandler{Handler: h, level: level, enabled: logger.HandlerLevel(h)}
+}
+
+// Enabled answers from the level of the wrapped handler, when it is
+// logger.Leveled.
+func (h *ErrorHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	if h.enabled != nil {
+		return level >= h.enabled.Level()
+	}
+	return h.Handler.Enabled(ctx, level)
+}
+
+func (h *ErrorHandler) Handle(ctx context.Context, r slog.Record) error {
+	if r.Level < h.level.Level() {
+		return h.Handler.Handle(ctx, r)
+	}
+
+	span := trace.SpanFromContext(ctx)
+	sc := span.SpanContext()
+	if !sc.IsValid() {
+		return h.Handler.Handle(ctx, r)
+	}
+
+	if span.IsRecording() {
+		err := recordError(r)
+		if err == nil {
+			err = errors.New(r.Message)
+		}
+		span.RecordError(err,
+			trace.WithStackTrace(true),
+			trace.WithAttributes(attribute.String("log.message", r.Message), attribute.String("log.level", r.Level.String())),
+		)
+		span.SetStatus(codes.Error, r.Message)
+	}
+
+	r = r.Clone()
+	r.AddAttrs(
+		slog.String(TraceIDKey, sc.TraceID().String()),
+		slog.String(SpanIDKey, sc.SpanID().String()),
+		slog.String(TraceFlagsKey, sc.TraceFlags().String()),
+	)
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h *ErrorHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &ErrorHandler{Handler: h.Handler.WithAttrs(attrs), level: h.level, enabled: h.enabled}
+}
+
+func (h *ErrorHandler) WithGroup(name string) slog.Handler {
+	return &ErrorHandler{Handler: h.Handler.WithGroup(name), level: h.level, enabled: h.enabled}
+}
+
+// recordError returns the first error valued attr of r.
+func recordError(r slog.Record) (err error) {
+	r.Attrs(func(a slog.Attr) bool {
+		if e, ok := a.Value.Resolve().Any().(error); ok {
+			err = e
+			return false
+		}
+		return true
+	})
+	return
+}
+
+// Sampled reports whether ctx carries a sampled span, for use with
+// logger.WithTraceAware.
+func Sampled(ctx context.Context) bool {
+	return trace.SpanContextFromContext(ctx).IsSampled()
+}
+
+// UseTracer makes logger.Span create spans with tracer and stamp their ids
+// on the records logged in them.
+//
+//	oteltrace.UseTracer(otel.Tracer("app"))
+func UseTracer(tracer trace.Tracer) {
+	logger.SetSpanStarter(func(ctx context.Context, name string) (context.Context, func(error)) {
+		ctx, span := tracer.Start(ctx, name)
+		sc := span.SpanContext()
+		if sc.IsValid() {
+			ctx = logger.ContextWithTrace(ctx, sc.TraceID().String(), sc.SpanID().String())
+			ctx = logger.ContextWithTraceFlags(ctx, byte(sc.TraceFlags()))
+		}
+		return ctx, func(err error) {
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+			}
+			span.End()
+		}
+	})
+}
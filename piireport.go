@@ -0,0 +1,255 @@
+package logger
+
+import (
+	"context"
+	"log/slog"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// PII classes reported by PIIReportHandler.
+const (
+	PIISecret = "secret"
+	PIIKey    = "pii_key"
+	PIIEmail  = "email"
+	PIICard   = "card_number"
+	PIIBearer = "bearer_token"
+	PIIPhone  = "phone"
+	PIIIP     = "ip_address"
+)
+
+// Indexes of the classes in piiClasses and bits of piiSample.classes.
+const (
+	piiClassSecret = iota
+	piiClassKey
+	piiClassEmail
+	piiClassCard
+	piiClassBearer
+	piiClassPhone
+	piiClassIP
+	piiClassCount
+)
+
+var piiClasses = [piiClassCount]string{PIISecret, PIIKey, PIIEmail, PIICard, PIIBearer, PIIPhone, PIIIP}
+
+var piiDetectors = []struct {
+	class int
+	re    *regexp.Regexp
+}{
+	{piiClassEmail, regexp.MustCompile(redactEmail)},
+	{piiClassCard, regexp.MustCompile(redactCard)},
+	{piiClassBearer, regexp.MustCompile(redactBearer)},
+	{piiClassPhone, regexp.MustCompile(`^\+?[0-9][0-9 ()-]{7,}[0-9]$`)},
+	{piiClassIP, regexp.MustCompile(`\b(?:[0-9]{1,3}\.){3}[0-9]{1,3}\b`)},
+}
+
+// maxPIIKeys bounds the keys PIIReportHandler keeps counts for; records
+// with more distinct keys are only counted under the keys already known.
+const maxPIIKeys = 1024
+
+// PIIKeyReport describes an attr key seen by PIIReportHandler: how many
+// records had it, and how many of those matched each PII class, by the key
+// name or by the value.
+type PIIKeyReport struct {
+	Key     string         `json:"key"`
+	Samples int            `json:"samples"`
+	Classes map[string]int `json:"classes,omitempty"`
+}
+
+// PIIReportHandler classifies the attrs of the records passing through
+// against PII detectors, to help privacy reviews of existing logging. It
+// never keeps values: every interval it logs a "pii report" record listing
+// the classified keys with their sample counts. Attrs from WithAttrs are
+// classified once.
+type PIIReportHandler struct {
+	slog.Handler
+	core    *piiCore
+	samples []piiSample
+	prefix  string
+}
+
+// piiSample is a classified attr: its dotted key and a bit per class of
+// piiClasses it matched.
+type piiSample struct {
+	key     string
+	classes uint8
+}
+
+type piiCore struct {
+	mu       sync.RWMutex
+	keys     map[string]*piiCounter
+	interval time.Duration
+	last     atomic.Int64
+	classes  map[string]int
+	root     slog.Handler
+}
+
+type piiCounter struct {
+	samples atomic.Int64
+	classes [piiClassCount]atomic.Int64
+}
+
+// logger.NewPIIReportHandler(h, time.Hour)
+func NewPIIReportHandler(h slog.Handler, interval time.Duration) *PIIReportHandler {
+	classes := make(map[string]int, len(redactSecrets)+len(redactPII))
+	for _, k := range redactPII {
+		classes[k] = piiClassKey
+	}
+	for _, k := range redactSecrets {
+		classes[k] = piiClassSecret
+	}
+	c := &piiCore{
+		keys:     map[string]*piiCounter{},
+		interval: interval,
+		classes:  classes,
+		root:     h,
+	}
+	c.last.Store(time.Now().UnixNano())
+	return &PIIReportHandler{Handler: h, core: c}
+}
+
+// Report returns the keys classified as PII so far, by key.
+func (h *PIIReportHandler) Report() []PIIKeyReport {
+	c := h.core
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	out := make([]PIIKeyReport, 0, len(c.keys))
+	for key, k := range c.keys {
+		rep := PIIKeyReport{Key: key, Samples: int(k.samples.Load())}
+		for i := range k.classes {
+			if n := k.classes[i].Load(); n > 0 {
+				if rep.Classes == nil {
+					rep.Classes = map[string]int{}
+				}
+				rep.Classes[piiClasses[i]] = int(n)
+			}
+		}
+		if len(rep.Classes) > 0 {
+			out = append(out, rep)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Key < out[j].Key })
+	return out
+}
+
+func (h *PIIReportHandler) Handle(ctx context.Context, r slog.Record) error {
+	c := h.core
+	for _, s := range h.samples {
+		c.count(s)
+	}
+	r.Attrs(func(a slog.Attr) bool {
+		c.classify(h.prefix, a, c.count)
+		return true
+	})
+
+	err := h.Handler.Handle(ctx, r)
+
+	now := time.Now()
+	last := c.last.Load()
+	if c.interval > 0 && now.UnixNano()-last >= int64(c.interval) && c.last.CompareAndSwap(last, now.UnixNano()) {
+		if report := h.Report(); len(report) > 0 {
+			h.logReport(ctx, now, report)
+		}
+	}
+	return err
+}
+
+func (h *PIIReportHandler) logReport(ctx context.Context, now time.Time, report []PIIKeyReport) {
+	attrs := make([]any, 0, len(report))
+	for _, k := range report {
+		classes := make([]string, 0, len(k.Classes))
+		for class := range k.Classes {
+			classes = append(classes, class)
+		}
+		sort.Strings(classes)
+		attrs = append(attrs, slog.Group(k.Key, "samples", k.Samples, "classes", strings.Join(classes, ",")))
+	}
+	r := slog.NewRecord(now, slog.LevelInfo, "pii report", 0)
+	r.AddAttrs(slog.Group("pii", attrs...))
+	_ = h.core.root.Handle(ctx, r)
+}
+
+// classify passes a, or the members of a group under their dotted keys, to
+// fn with the classes they match.
+func (c *piiCore) classify(prefix string, a slog.Attr, fn func(piiSample)) {
+	v := a.Value.Resolve()
+	if v.Kind() == slog.KindGroup {
+		if a.Key != "" {
+			prefix += a.Key + "."
+		}
+		for _, ga := range v.Group() {
+			c.classify(prefix, ga, fn)
+		}
+		return
+	}
+	if a.Key == "" {
+		return
+	}
+
+	s := piiSample{key: prefix + a.Key}
+	name := s.key[strings.LastIndexByte(s.key, '.')+1:]
+	if class, ok := c.classes[strings.ToLower(name)]; ok {
+		s.classes |= 1 << class
+	}
+	if v.Kind() == slog.KindString {
+		str := v.String()
+		for _, d := range piiDetectors {
+			if d.re.MatchString(str) {
+				s.classes |= 1 << d.class
+			}
+		}
+	}
+	fn(s)
+}
+
+// count adds s to the counts of its key.
+func (c *piiCore) count(s piiSample) {
+	c.mu.RLock()
+	k, ok := c.keys[s.key]
+	c.mu.RUnlock()
+	if !ok {
+		c.mu.Lock()
+		if k, ok = c.keys[s.key]; !ok {
+			if len(c.keys) >= maxPIIKeys {
+				c.mu.Unlock()
+				return
+			}
+			k = &piiCounter{}
+			c.keys[s.key] = k
+		}
+		c.mu.Unlock()
+	}
+
+	k.samples.Add(1)
+	for i := range k.classes {
+		if s.classes&(1<<i) != 0 {
+			k.classes[i].Add(1)
+		}
+	}
+}
+
+func (h *PIIReportHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	nh := *h
+	nh.Handler = h.Handler.WithAttrs(attrs)
+	nh.samples = append([]piiSample(nil), h.samples...)
+	for _, a := range attrs {
+		h.core.classify(h.prefix, a, func(s piiSample) {
+			nh.samples = append(nh.samples, s)
+		})
+	}
+	return &nh
+}
+func (h *PIIReportHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	nh := *h
+	nh.Handler = h.Handler.WithGroup(name)
+	nh.prefix = h.prefix + name + "."
+	return &nh
+}
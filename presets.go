@@ -0,0 +1,29 @@
+package logger
+
+import "time"
+
+// NewDevelopmentBuilder returns a Builder for local development: colored
+// FastText output at DEBUG with multi-line errors. options are applied
+// after the preset ones.
+//
+//	slog.SetDefault(slog.New(logger.NewDevelopmentBuilder().Build()))
+func NewDevelopmentBuilder(options ...Option) *Builder {
+	return NewBuilder(append([]Option{
+		WithFastText(true),
+		WithLevelStyles(DefaultLevelStyles),
+		WithLevel(LevelDebug),
+		WithMultilineErrors(),
+	}, options...)...)
+}
+
+// NewProductionBuilder returns a Builder for production: JSON at INFO,
+// keeping the first 100 records per level and message each second and
+// every 100th after them. options are applied after the preset ones.
+//
+//	slog.SetDefault(slog.New(logger.NewProductionBuilder().Build()))
+func NewProductionBuilder(options ...Option) *Builder {
+	return NewBuilder(append([]Option{
+		WithJSON(true),
+		WithLevel(LevelInfo),
+	}, options...)...).WithSampling(WithSampling(time.Second, 100, 100))
+}
@@ -0,0 +1,101 @@
+package logger
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	HeaderRequestID   string = "X-Request-Id"
+	HeaderTraceParent string = "traceparent"
+	HeaderLogAttrs    string = "X-Log-Attrs"
+)
+
+// HeaderCarrier is implemented by message header adapters (Kafka record
+// headers, NATS headers, AMQP table, ...).
+type HeaderCarrier interface {
+	Get(key string) string
+	Set(key, value string)
+}
+
+type MapCarrier map[string]string
+
+func (c MapCarrier) Get(key string) string {
+	return c[key]
+}
+
+func (c MapCarrier) Set(key, value string) {
+	c[key] = value
+}
+
+// InjectContext sets the request id, the traceparent of the trace with its
+// flags, and the context attrs in carrier.
+//
+// producer:
+// headers := logger.MapCarrier{}
+// logger.InjectContext(ctx, headers)
+//
+// consumer:
+// ctx := logger.ExtractContext(context.Background(), logger.MapCarrier(msg.Headers))
+func InjectContext(ctx context.Context, carrier HeaderCarrier) {
+	if id := RequestIDFromContext(ctx); id != "" {
+		carrier.Set(HeaderRequestID, id)
+	}
+	if traceID, spanID := TraceFromContext(ctx); traceID != "" && spanID != "" {
+		// without flags no sampling decision is propagated
+		flags, _ := TraceFlagsFromContext(ctx)
+		carrier.Set(HeaderTraceParent, fmt.Sprintf("00-%s-%s-%02x", traceID, spanID, flags))
+	}
+	if attrs := AttrsFromContext(ctx); len(attrs) > 0 {
+		if b, err := json.Marshal(attrsToMap(attrs)); err == nil {
+			carrier.Set(HeaderLogAttrs, string(b))
+		}
+	}
+}
+
+func ExtractContext(ctx context.Context, carrier HeaderCarrier) context.Context {
+	if id := carrier.Get(HeaderRequestID); id != "" {
+		ctx = ContextWithRequestID(ctx, id)
+	}
+	if parts := strings.Split(carrier.Get(HeaderTraceParent), "-"); len(parts) == 4 && len(parts[1]) == 32 && len(parts[2]) == 16 {
+		ctx = ContextWithTrace(ctx, parts[1], parts[2])
+		if flags, err := strconv.ParseUint(parts[3], 16, 8); err == nil && len(parts[3]) == 2 {
+			ctx = ContextWithTraceFlags(ctx, byte(flags))
+		}
+	}
+	if s := carrier.Get(HeaderLogAttrs); s != "" {
+		var m map[string]any
+		if json.Unmarshal([]byte(s), &m) == nil {
+			keys := make([]string, 0, len(m))
+			for k := range m {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+
+			attrs := make([]slog.Attr, 0, len(keys))
+			for _, k := range keys {
+				attrs = append(attrs, jsonAttr(k, m[k]))
+			}
+			ctx = ContextWithAttrs(ctx, attrs...)
+		}
+	}
+	return ctx
+}
+
+func attrsToMap(attrs []slog.Attr) map[string]any {
+	m := make(map[string]any, len(attrs))
+	for _, a := range attrs {
+		v := a.Value.Resolve()
+		if v.Kind() == slog.KindGroup {
+			m[a.Key] = attrsToMap(v.Group())
+			continue
+		}
+		m[a.Key] = v.Any()
+	}
+	return m
+}
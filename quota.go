@@ -0,0 +1,141 @@
+package logger
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+const ComponentKey string = "component"
+
+// QuotaPolicy limits the records and bytes a component may log per Window.
+// Once exceeded, records are dropped until the window ends, or with
+// Downgrade only records at WARN and above are kept.
+type QuotaPolicy struct {
+	Window    time.Duration `json:"window,omitempty"`
+	Count     int64         `json:"count,omitempty"`
+	Bytes     int64         `json:"bytes,omitempty"`
+	Downgrade bool          `json:"downgrade,omitempty"`
+}
+
+// QuotaHandler enforces a QuotaPolicy per value of the component attr and
+// emits a single WARN meta record each window a component goes over quota.
+// Policies are looked up by component name, falling back to the "*" entry.
+//
+// Up to maxQuotas components get a quota of their own; beyond that the
+// components without a policy of their own share the "*" quota, so
+// high-cardinality component values cannot grow memory without bound.
+type QuotaHandler struct {
+	slog.Handler
+	core      *quotaCore
+	component string
+	grouped   bool
+}
+
+// maxQuotas bounds the components a QuotaHandler keeps a quota for, besides
+// those with a policy of their own.
+const maxQuotas = 1024
+
+type quotaCore struct {
+	base     slog.Handler
+	key      string
+	policies map[string]QuotaPolicy
+
+	mu     sync.Mutex
+	quotas map[string]*windowQuota
+}
+
+//	logger.NewQuotaHandler(h, logger.ComponentKey, map[string]logger.QuotaPolicy{
+//		"*": {Window: time.Minute, Count: 1000, Downgrade: true},
+//	})
+func NewQuotaHandler(h slog.Handler, key string, policies map[string]QuotaPolicy) *QuotaHandler {
+	return &QuotaHandler{
+		Handler: h,
+		core: &quotaCore{
+			base:     h,
+			key:      key,
+			policies: policies,
+			quotas:   make(map[string]*windowQuota),
+		},
+	}
+}
+
+// quota returns the quota of component, the name it is kept under and its
+// policy.
+func (c *quotaCore) quota(component string) (*windowQuota, string, QuotaPolicy) {
+	p, own := c.policies[component]
+	ok := own
+	if !ok {
+		p, ok = c.policies["*"]
+	}
+	if !ok || (p.Count <= 0 && p.Bytes <= 0) {
+		return nil, component, p
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	q, ok := c.quotas[component]
+	if !ok {
+		if !own && len(c.quotas) >= maxQuotas {
+			component = "*"
+			if q, ok = c.quotas[component]; ok {
+				return q, component, p
+			}
+		}
+		q = newWindowQuota(p.Window, p.Count, p.Bytes)
+		c.quotas[component] = q
+	}
+	return q, component, p
+}
+
+func (h *QuotaHandler) Handle(ctx context.Context, r slog.Record) error {
+	component := h.component
+	r.Attrs(func(a slog.Attr) bool {
+		if a.Key == h.core.key {
+			component = a.Value.String()
+			return false
+		}
+		return true
+	})
+
+	q, component, p := h.core.quota(component)
+	if q == nil || forced(ctx, r) {
+		return h.Handler.Handle(ctx, r)
+	}
+
+	ok, first := q.allow(r.Time, recordSize(r))
+	if first {
+		meta := slog.NewRecord(r.Time, slog.LevelWarn, "log quota exceeded", 0)
+		meta.AddAttrs(
+			slog.String(h.core.key, component),
+			slog.Duration("window", q.window),
+			slog.Int64("quota_count", p.Count),
+			slog.Int64("quota_bytes", p.Bytes),
+		)
+		if err := h.core.base.Handle(ctx, meta); err != nil {
+			return err
+		}
+	}
+	if !ok && !(p.Downgrade && r.Level >= slog.LevelWarn) {
+		return nil
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h *QuotaHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	component := h.component
+	if !h.grouped {
+		for _, a := range attrs {
+			if a.Key == h.core.key {
+				component = a.Value.String()
+			}
+		}
+	}
+	return &QuotaHandler{Handler: h.Handler.WithAttrs(attrs), core: h.core, component: component, grouped: h.grouped}
+}
+
+func (h *QuotaHandler) WithGroup(name string) slog.Handler {
+	return &QuotaHandler{Handler: h.Handler.WithGroup(name), core: h.core, component: h.component, grouped: true}
+}
@@ -0,0 +1,39 @@
+package logger
+
+import (
+	"fmt"
+	"log/slog"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestQuotaHandlerBoundsComponents(t *testing.T) {
+	var n atomic.Int64
+	h := NewQuotaHandler(countHandler{&n}, ComponentKey, map[string]QuotaPolicy{
+		"*":        {Window: time.Hour, Count: 1},
+		"payments": {Window: time.Hour, Count: 1},
+	})
+	l := slog.New(h)
+
+	for i := 0; i < maxQuotas; i++ {
+		l.Info("hello", ComponentKey, fmt.Sprint("c", i))
+	}
+	if got := n.Load(); got != maxQuotas {
+		t.Fatalf("handled %d records, want %d", got, maxQuotas)
+	}
+
+	// new components share the "*" quota, components with a policy of
+	// their own keep it
+	n.Store(0)
+	l.Info("hello", ComponentKey, "overflow1")
+	l.Info("hello", ComponentKey, "overflow2")
+	l.Info("hello", ComponentKey, "payments")
+	// overflow1, the quota exceeded meta record for overflow2, payments
+	if got := n.Load(); got != 3 {
+		t.Errorf("handled %d records, want 3", got)
+	}
+	if got, max := len(h.core.quotas), maxQuotas+2; got > max {
+		t.Errorf("kept %d quotas, want at most %d", got, max)
+	}
+}
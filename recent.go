@@ -0,0 +1,170 @@
+package logger
+
+import (
+	"context"
+	"hash/fnv"
+	"log/slog"
+	"math"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// RecentError is an ERROR message tracked by RecentErrorsHandler.
+type RecentError struct {
+	Message     string    `json:"message"`
+	Fingerprint string    `json:"fingerprint"`
+	First       time.Time `json:"first"`
+	Last        time.Time `json:"time"`
+	Count       int       `json:"count"`
+}
+
+// recentErrors is the reservoir RecentErrors reads; the last created
+// RecentErrorsHandler feeds it.
+var recentErrors struct {
+	mu sync.Mutex
+	r  *errorReservoir
+}
+
+// RecentErrors returns the errors of the reservoir, most recent first.
+func RecentErrors() []RecentError {
+	recentErrors.mu.Lock()
+	r := recentErrors.r
+	recentErrors.mu.Unlock()
+	if r == nil {
+		return nil
+	}
+	return r.snapshot()
+}
+
+// errorReservoir keeps up to size fingerprints. Each has a score that
+// decays by half every halfLife and grows by one per occurrence; when full,
+// the lowest scoring fingerprint makes room, so frequent and recent errors
+// stay while one-off old ones age out.
+type errorReservoir struct {
+	size     int
+	halfLife time.Duration
+
+	mu      sync.Mutex
+	entries map[string]*errorEntry
+}
+
+type errorEntry struct {
+	RecentError
+	score float64
+	at    time.Time
+}
+
+// RecentErrorsHandler records ERROR records in the reservoir read by
+// RecentErrors.
+type RecentErrorsHandler struct {
+	slog.Handler
+	r *errorReservoir
+}
+
+// logger.NewRecentErrorsHandler(h, 100, 10*time.Minute)
+func NewRecentErrorsHandler(h slog.Handler, size int, halfLife time.Duration) *RecentErrorsHandler {
+	r := &errorReservoir{size: size, halfLife: halfLife, entries: make(map[string]*errorEntry)}
+
+	recentErrors.mu.Lock()
+	recentErrors.r = r
+	recentErrors.mu.Unlock()
+
+	return &RecentErrorsHandler{Handler: h, r: r}
+}
+
+func (h *RecentErrorsHandler) Handle(ctx context.Context, r slog.Record) error {
+	if r.Level >= slog.LevelError {
+		now := r.Time
+		if now.IsZero() {
+			now = time.Now()
+		}
+		h.r.add(r.Message, now)
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h *RecentErrorsHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &RecentErrorsHandler{Handler: h.Handler.WithAttrs(attrs), r: h.r}
+}
+
+func (h *RecentErrorsHandler) WithGroup(name string) slog.Handler {
+	return &RecentErrorsHandler{Handler: h.Handler.WithGroup(name), r: h.r}
+}
+
+func (r *errorReservoir) decayed(e *errorEntry, now time.Time) float64 {
+	if r.halfLife <= 0 {
+		return e.score
+	}
+	return e.score * math.Exp2(-float64(now.Sub(e.at))/float64(r.halfLife))
+}
+
+func (r *errorReservoir) add(msg string, now time.Time) {
+	fp := fingerprint(msg)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if e, ok := r.entries[fp]; ok {
+		e.score = r.decayed(e, now) + 1
+		e.at = now
+		e.Message = msg
+		e.Last = now
+		e.Count++
+		return
+	}
+
+	if r.size < 1 {
+		return
+	}
+	if len(r.entries) >= r.size {
+		var (
+			lowest string
+			low    = math.Inf(1)
+		)
+		for k, e := range r.entries {
+			if s := r.decayed(e, now); s < low {
+				lowest, low = k, s
+			}
+		}
+		delete(r.entries, lowest)
+	}
+	r.entries[fp] = &errorEntry{
+		RecentError: RecentError{Message: msg, Fingerprint: fp, First: now, Last: now, Count: 1},
+		score:       1,
+		at:          now,
+	}
+}
+
+func (r *errorReservoir) snapshot() []RecentError {
+	r.mu.Lock()
+	out := make([]RecentError, 0, len(r.entries))
+	for _, e := range r.entries {
+		out = append(out, e.RecentError)
+	}
+	r.mu.Unlock()
+
+	sort.Slice(out, func(i, j int) bool { return out[i].Last.After(out[j].Last) })
+	return out
+}
+
+// fingerprint hashes msg with digit runs collapsed, so messages differing
+// only in ids, counts or durations share a fingerprint.
+func fingerprint(msg string) string {
+	f := fnv.New64a()
+	digits := false
+	for i := 0; i < len(msg); i++ {
+		c := msg[i]
+		if c >= '0' && c <= '9' {
+			if !digits {
+				f.Write([]byte{'#'})
+			}
+			digits = true
+			continue
+		}
+		digits = false
+		f.Write([]byte{c})
+	}
+	return strconv.FormatUint(f.Sum64(), 16)
+}
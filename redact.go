@@ -0,0 +1,211 @@
+package logger
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"os"
+	"regexp"
+	"strings"
+)
+
+const RedactedValue string = "[REDACTED]"
+
+// RedactProfileEnv names the environment variable RedactProfileFromEnv reads.
+const RedactProfileEnv string = "LOG_REDACT_PROFILE"
+
+// RedactHashKeyEnv names the environment variable RedactProfileFromEnv reads
+// the HashKey from.
+const RedactHashKeyEnv string = "LOG_REDACT_HASH_KEY"
+
+// RedactProfile lists attr keys whose values are replaced with RedactedValue,
+// and keys whose values are replaced with a hash, which hides them while
+// keeping records of the same user or client correlatable. Keys are matched
+// case-insensitively at any group depth. Matches of Patterns in string
+// values are redacted too.
+//
+// Values are hashed with HMAC-SHA256 keyed with HashKey, so they can't be
+// recovered by hashing guesses. Records hashed with the same key correlate;
+// without one a random key is generated per handler.
+type RedactProfile struct {
+	Keys     []string `json:"keys,omitempty"`
+	HashKeys []string `json:"hash_keys,omitempty"`
+	Patterns []string `json:"patterns,omitempty"`
+	HashKey  []byte   `json:"-"`
+}
+
+var (
+	redactSecrets = []string{
+		"password", "passwd", "secret", "token", "access_token", "refresh_token",
+		"authorization", "cookie", "set-cookie", "api_key", "apikey", "private_key",
+	}
+	redactPII = []string{"email", "phone", "ssn", "card_number", "iban", "address", "client_ip"}
+
+	redactBearer = `(?i)bearer\s+[a-z0-9._~+/=-]+`
+	redactCard   = `\b(?:\d[ -]?){12,15}\d\b`
+	redactEmail  = `[a-zA-Z0-9._%+-]+@[a-zA-Z0-9.-]+\.[a-zA-Z]{2,}`
+)
+
+// RedactProfiles are the built-in profiles: "strict" masks secrets and
+// personal data, "standard" masks secrets and hashes personal data, and
+// "debug-local" only masks secrets.
+var RedactProfiles = map[string]RedactProfile{
+	"strict": {
+		Keys:     append(append([]string{}, redactSecrets...), redactPII...),
+		Patterns: []string{redactBearer, redactCard, redactEmail},
+	},
+	"standard": {
+		Keys:     redactSecrets,
+		HashKeys: redactPII,
+		Patterns: []string{redactBearer, redactCard},
+	},
+	"debug-local": {
+		Keys: redactSecrets,
+	},
+}
+
+// RedactProfileFromEnv returns the built-in profile named by
+// LOG_REDACT_PROFILE, "standard" if it is unset, so the same binary can be
+// deployed to environments with different regulatory requirements. Its
+// HashKey is LOG_REDACT_HASH_KEY.
+func RedactProfileFromEnv() (RedactProfile, error) {
+	name := os.Getenv(RedactProfileEnv)
+	if name == "" {
+		name = "standard"
+	}
+	p, ok := RedactProfiles[name]
+	if !ok {
+		return RedactProfile{}, fmt.Errorf("unknown redact profile %q", name)
+	}
+	if key := os.Getenv(RedactHashKeyEnv); key != "" {
+		p.HashKey = []byte(key)
+	}
+	return p, nil
+}
+
+type RedactHandler struct {
+	slog.Handler
+	keys     map[string]bool
+	patterns []*regexp.Regexp
+	hashKey  []byte
+}
+
+// logger.NewRedactHandler(h, logger.RedactProfile{Keys: []string{"password", "token"}})
+// logger.NewRedactHandler(h, logger.RedactProfiles["strict"])
+//
+// Invalid patterns are ignored.
+func NewRedactHandler(h slog.Handler, p RedactProfile) *RedactHandler {
+	keys := make(map[string]bool, len(p.Keys)+len(p.HashKeys))
+	for _, k := range p.HashKeys {
+		keys[strings.ToLower(k)] = true
+	}
+	for _, k := range p.Keys {
+		keys[strings.ToLower(k)] = false
+	}
+	rh := &RedactHandler{Handler: h, keys: keys, hashKey: p.HashKey}
+	if len(p.HashKeys) > 0 && len(rh.hashKey) == 0 {
+		rh.hashKey = make([]byte, 32)
+		_, _ = rand.Read(rh.hashKey)
+	}
+	for _, pattern := range p.Patterns {
+		if re, err := regexp.Compile(pattern); err == nil {
+			rh.patterns = append(rh.patterns, re)
+		}
+	}
+	return rh
+}
+
+func (h *RedactHandler) enabled() bool {
+	return len(h.keys) > 0 || len(h.patterns) > 0
+}
+
+func (h *RedactHandler) Handle(ctx context.Context, r slog.Record) error {
+	if !h.enabled() {
+		return h.Handler.Handle(ctx, r)
+	}
+
+	nr := slog.NewRecord(r.Time, r.Level, h.redactString(r.Message), r.PC)
+	r.Attrs(func(a slog.Attr) bool {
+		nr.AddAttrs(h.redact(a))
+		return true
+	})
+	return h.Handler.Handle(ctx, nr)
+}
+
+func (h *RedactHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	out := make([]slog.Attr, len(attrs))
+	for i, a := range attrs {
+		out[i] = h.redact(a)
+	}
+	return &RedactHandler{Handler: h.Handler.WithAttrs(out), keys: h.keys, patterns: h.patterns, hashKey: h.hashKey}
+}
+
+func (h *RedactHandler) WithGroup(name string) slog.Handler {
+	return &RedactHandler{Handler: h.Handler.WithGroup(name), keys: h.keys, patterns: h.patterns, hashKey: h.hashKey}
+}
+
+func (h *RedactHandler) redact(a slog.Attr) slog.Attr {
+	if hash, ok := h.keys[strings.ToLower(a.Key)]; ok {
+		if hash {
+			return slog.String(a.Key, h.hash(a.Value.Resolve().String()))
+		}
+		return slog.String(a.Key, RedactedValue)
+	}
+	switch a.Value.Kind() {
+	case slog.KindGroup:
+		group := a.Value.Group()
+		members := make([]slog.Attr, len(group))
+		for i, ga := range group {
+			members[i] = h.redact(ga)
+		}
+		return slog.Attr{Key: a.Key, Value: slog.GroupValue(members...)}
+	case slog.KindString:
+		if len(h.patterns) > 0 {
+			return slog.String(a.Key, h.redactString(a.Value.String()))
+		}
+	}
+	return a
+}
+
+func (h *RedactHandler) redactString(s string) string {
+	for _, re := range h.patterns {
+		s = re.ReplaceAllLiteralString(s, RedactedValue)
+	}
+	return s
+}
+
+// hash returns a short keyed digest of s.
+func (h *RedactHandler) hash(s string) string {
+	mac := hmac.New(sha256.New, h.hashKey)
+	mac.Write([]byte(s))
+	return "hmac:" + hex.EncodeToString(mac.Sum(nil)[:16])
+}
+
+// redactMap returns a copy of the flattened attrs m with the values redacted
+// whose dotted key has a redacted segment.
+func (h *RedactHandler) redactMap(m map[string]any) map[string]any {
+	if !h.enabled() || len(m) == 0 {
+		return m
+	}
+	out := make(map[string]any, len(m))
+	for k, v := range m {
+		out[k] = v
+		if s, ok := v.(string); ok {
+			out[k] = h.redactString(s)
+		}
+		for _, seg := range strings.Split(k, ".") {
+			if hash, ok := h.keys[strings.ToLower(seg)]; ok {
+				out[k] = RedactedValue
+				if hash {
+					out[k] = h.hash(fmt.Sprint(v))
+				}
+				break
+			}
+		}
+	}
+	return out
+}
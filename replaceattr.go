@@ -0,0 +1,125 @@
+package logger
+
+import (
+	"log/slog"
+	"math"
+	"strings"
+	"unicode/utf8"
+)
+
+// ReplaceAttrFunc is the signature of slog.HandlerOptions.ReplaceAttr.
+type ReplaceAttrFunc func(groups []string, a slog.Attr) slog.Attr
+
+// ChainReplaceAttr applies fns in order, stopping once one of them drops the
+// attr.
+//
+//	&slog.HandlerOptions{ReplaceAttr: logger.ChainReplaceAttr(
+//		logger.DropKeys("password"),
+//		logger.RenameKey(slog.MessageKey, "message"),
+//		logger.TruncateStrings(1024),
+//	)}
+func ChainReplaceAttr(fns ...ReplaceAttrFunc) ReplaceAttrFunc {
+	return func(groups []string, a slog.Attr) slog.Attr {
+		for _, fn := range fns {
+			if fn == nil {
+				continue
+			}
+			a = fn(groups, a)
+			if a.Equal(slog.Attr{}) {
+				return a
+			}
+		}
+		return a
+	}
+}
+
+// WithReplaceAttr adds fns to the ReplaceAttr of the handler built by
+// NewLogger, applied before its own caller and time formatting. They do not
+// apply to FastTextHandler.
+func WithReplaceAttr(fns ...ReplaceAttrFunc) Option {
+	return func(opts *loggerOptions) {
+		opts.replaceAttr = append(opts.replaceAttr, fns...)
+	}
+}
+
+// DropKeys drops attrs with any of keys, in any group.
+func DropKeys(keys ...string) ReplaceAttrFunc {
+	drop := make(map[string]struct{}, len(keys))
+	for _, k := range keys {
+		drop[k] = struct{}{}
+	}
+	return func(_ []string, a slog.Attr) slog.Attr {
+		if _, ok := drop[a.Key]; ok {
+			return slog.Attr{}
+		}
+		return a
+	}
+}
+
+// RenameKey renames the top-level attr from to to, e.g. the built-in
+// slog.MessageKey.
+func RenameKey(from, to string) ReplaceAttrFunc {
+	return func(groups []string, a slog.Attr) slog.Attr {
+		if len(groups) == 0 && a.Key == from {
+			a.Key = to
+		}
+		return a
+	}
+}
+
+// TruncateStrings cuts string values longer than n bytes, at a rune
+// boundary, and marks them with a trailing "...". A negative n is taken as
+// zero.
+func TruncateStrings(n int) ReplaceAttrFunc {
+	n = max(n, 0)
+	return func(_ []string, a slog.Attr) slog.Attr {
+		if a.Value.Kind() != slog.KindString {
+			return a
+		}
+		s := a.Value.String()
+		if len(s) <= n {
+			return a
+		}
+		cut := n
+		for cut > 0 && !utf8.RuneStart(s[cut]) {
+			cut--
+		}
+		return slog.String(a.Key, s[:cut]+"...")
+	}
+}
+
+// RoundFloats rounds float values to digits decimal places.
+func RoundFloats(digits int) ReplaceAttrFunc {
+	scale := math.Pow10(digits)
+	return func(_ []string, a slog.Attr) slog.Attr {
+		if a.Value.Kind() != slog.KindFloat64 {
+			return a
+		}
+		f := a.Value.Float64()
+		if math.IsNaN(f) || math.IsInf(f, 0) {
+			return a
+		}
+		return slog.Float64(a.Key, math.Round(f*scale)/scale)
+	}
+}
+
+// LowercaseLevel writes the level as "info" instead of "INFO".
+func LowercaseLevel() ReplaceAttrFunc {
+	return func(groups []string, a slog.Attr) slog.Attr {
+		if len(groups) == 0 && a.Key == slog.LevelKey {
+			return slog.String(a.Key, strings.ToLower(a.Value.String()))
+		}
+		return a
+	}
+}
+
+// RFC3339Time writes the record time as RFC 3339 with millisecond precision
+// in UTC.
+func RFC3339Time() ReplaceAttrFunc {
+	return func(groups []string, a slog.Attr) slog.Attr {
+		if len(groups) == 0 && a.Key == slog.TimeKey && a.Value.Kind() == slog.KindTime {
+			return slog.String(a.Key, a.Value.Time().UTC().Format("2006-01-02T15:04:05.000Z07:00"))
+		}
+		return a
+	}
+}
@@ -0,0 +1,279 @@
+// Package replay records slog records as portable JSON envelopes and feeds
+// them back into any handler, e.g. to re-encode archived logs for another
+// backend or to run encoder regression tests against recorded input.
+//
+//	rec := replay.NewHandler(f)
+//	...
+//	n, err := replay.Replay(ctx, f, ecsHandler)
+package replay
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Envelope is a record with the kind of every attr value kept, so that
+// replaying it yields the same slog.Kinds. Handler attrs and groups are
+// folded into Attrs.
+type Envelope struct {
+	Time    time.Time  `json:"time"`
+	Level   slog.Level `json:"level"`
+	Message string     `json:"msg"`
+	Attrs   []Attr     `json:"attrs,omitempty"`
+}
+
+// Attr is an encoded slog.Attr. Scalar values are kept as strings to
+// preserve int64 precision and non-finite floats; Any values are JSON.
+type Attr struct {
+	Key   string          `json:"key"`
+	Kind  string          `json:"kind"`
+	Value json.RawMessage `json:"value,omitempty"`
+	Group []Attr          `json:"group,omitempty"`
+}
+
+// Handler writes one Envelope per line to w.
+type Handler struct {
+	w      io.Writer
+	mu     *sync.Mutex
+	level  slog.Leveler
+	frames []frame
+}
+
+// frame is a group opened by WithGroup, with the attrs added inside it,
+// encoded once by WithAttrs.
+type frame struct {
+	name  string
+	attrs []Attr
+}
+
+type Option func(*handlerOptions)
+
+type handlerOptions struct {
+	level slog.Leveler
+}
+
+// WithLevel records only records at or above level. Default: all levels.
+func WithLevel(level slog.Leveler) Option {
+	return func(opts *handlerOptions) {
+		opts.level = level
+	}
+}
+
+func HandlerOptions(options ...Option) *handlerOptions {
+	opts := &handlerOptions{level: slog.Level(math.MinInt)}
+	for _, opt := range options {
+		opt(opts)
+	}
+	return opts
+}
+
+// rec := replay.NewHandler(f, replay.WithLevel(slog.LevelInfo))
+func NewHandler(w io.Writer, options ...Option) *Handler {
+	opts := HandlerOptions(options...)
+	return &Handler{w: w, mu: &sync.Mutex{}, level: opts.level, frames: []frame{{}}}
+}
+
+func (h *Handler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= h.level.Level()
+}
+
+func (h *Handler) Handle(_ context.Context, r slog.Record) error {
+	last := len(h.frames) - 1
+	attrs := make([]Attr, 0, len(h.frames[last].attrs)+r.NumAttrs())
+	attrs = append(attrs, h.frames[last].attrs...)
+	r.Attrs(func(a slog.Attr) bool {
+		attrs = append(attrs, encodeAttr(a))
+		return true
+	})
+	for i := last; i > 0; i-- {
+		parent := append([]Attr(nil), h.frames[i-1].attrs...)
+		if len(attrs) > 0 {
+			parent = append(parent, Attr{Key: h.frames[i].name, Kind: slog.KindGroup.String(), Group: attrs})
+		}
+		attrs = parent
+	}
+
+	e := Envelope{Time: r.Time, Level: r.Level, Message: r.Message, Attrs: attrs}
+	b, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	_, err = h.w.Write(append(b, '\n'))
+	return err
+}
+
+func (h *Handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+	h2 := *h
+	h2.frames = append([]frame(nil), h.frames...)
+	last := &h2.frames[len(h2.frames)-1]
+	last.attrs = append(append([]Attr(nil), last.attrs...), encodeAttrs(attrs)...)
+	return &h2
+}
+
+func (h *Handler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	h2 := *h
+	h2.frames = append(append([]frame(nil), h.frames...), frame{name: name})
+	return &h2
+}
+
+func encodeAttrs(attrs []slog.Attr) []Attr {
+	out := make([]Attr, 0, len(attrs))
+	for _, a := range attrs {
+		out = append(out, encodeAttr(a))
+	}
+	return out
+}
+
+func encodeAttr(a slog.Attr) Attr {
+	v := a.Value.Resolve()
+	e := Attr{Key: a.Key, Kind: v.Kind().String()}
+	switch v.Kind() {
+	case slog.KindGroup:
+		e.Group = encodeAttrs(v.Group())
+	case slog.KindString:
+		e.Value = jsonString(v.String())
+	case slog.KindInt64:
+		e.Value = jsonString(strconv.FormatInt(v.Int64(), 10))
+	case slog.KindUint64:
+		e.Value = jsonString(strconv.FormatUint(v.Uint64(), 10))
+	case slog.KindFloat64:
+		e.Value = jsonString(strconv.FormatFloat(v.Float64(), 'g', -1, 64))
+	case slog.KindBool:
+		e.Value = jsonString(strconv.FormatBool(v.Bool()))
+	case slog.KindDuration:
+		e.Value = jsonString(strconv.FormatInt(int64(v.Duration()), 10))
+	case slog.KindTime:
+		e.Value = jsonString(v.Time().Format(time.RFC3339Nano))
+	default:
+		val := v.Any()
+		if err, ok := val.(error); ok {
+			val = err.Error()
+		}
+		b, err := json.Marshal(val)
+		if err != nil {
+			b = jsonString(fmt.Sprint(val))
+		}
+		e.Value = b
+	}
+	return e
+}
+
+func jsonString(s string) json.RawMessage {
+	b, _ := json.Marshal(s)
+	return b
+}
+
+// Record decodes e into a slog.Record.
+func (e Envelope) Record() (slog.Record, error) {
+	attrs, err := decodeAttrs(e.Attrs)
+	if err != nil {
+		return slog.Record{}, err
+	}
+	r := slog.NewRecord(e.Time, e.Level, e.Message, 0)
+	r.AddAttrs(attrs...)
+	return r, nil
+}
+
+func decodeAttrs(attrs []Attr) ([]slog.Attr, error) {
+	out := make([]slog.Attr, 0, len(attrs))
+	for _, a := range attrs {
+		v, err := a.value()
+		if err != nil {
+			return nil, fmt.Errorf("attr %q: %w", a.Key, err)
+		}
+		out = append(out, slog.Attr{Key: a.Key, Value: v})
+	}
+	return out, nil
+}
+
+func (a Attr) value() (slog.Value, error) {
+	if a.Kind == slog.KindGroup.String() {
+		attrs, err := decodeAttrs(a.Group)
+		return slog.GroupValue(attrs...), err
+	}
+	if a.Kind == slog.KindAny.String() || a.Kind == slog.KindLogValuer.String() {
+		var v any
+		if len(a.Value) > 0 {
+			if err := json.Unmarshal(a.Value, &v); err != nil {
+				return slog.Value{}, err
+			}
+		}
+		return slog.AnyValue(v), nil
+	}
+
+	var s string
+	if err := json.Unmarshal(a.Value, &s); err != nil {
+		return slog.Value{}, err
+	}
+	switch a.Kind {
+	case slog.KindString.String():
+		return slog.StringValue(s), nil
+	case slog.KindInt64.String():
+		n, err := strconv.ParseInt(s, 10, 64)
+		return slog.Int64Value(n), err
+	case slog.KindUint64.String():
+		n, err := strconv.ParseUint(s, 10, 64)
+		return slog.Uint64Value(n), err
+	case slog.KindFloat64.String():
+		f, err := strconv.ParseFloat(s, 64)
+		return slog.Float64Value(f), err
+	case slog.KindBool.String():
+		b, err := strconv.ParseBool(s)
+		return slog.BoolValue(b), err
+	case slog.KindDuration.String():
+		n, err := strconv.ParseInt(s, 10, 64)
+		return slog.DurationValue(time.Duration(n)), err
+	case slog.KindTime.String():
+		t, err := time.Parse(time.RFC3339Nano, s)
+		return slog.TimeValue(t), err
+	}
+	return slog.Value{}, fmt.Errorf("unknown kind %q", a.Kind)
+}
+
+// Replay reads envelopes from r, one per line, and passes the records that
+// h is enabled for to h. It returns the number of records handled and stops
+// at the first error.
+func Replay(ctx context.Context, r io.Reader, h slog.Handler) (int, error) {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 0, 64<<10), 16<<20)
+
+	n := 0
+	for line := 1; sc.Scan(); line++ {
+		if len(sc.Bytes()) == 0 {
+			continue
+		}
+		var e Envelope
+		if err := json.Unmarshal(sc.Bytes(), &e); err != nil {
+			return n, fmt.Errorf("replay: line %d: %w", line, err)
+		}
+		rec, err := e.Record()
+		if err != nil {
+			return n, fmt.Errorf("replay: line %d: %w", line, err)
+		}
+		if !h.Enabled(ctx, rec.Level) {
+			continue
+		}
+		if err := h.Handle(ctx, rec); err != nil {
+			return n, err
+		}
+		n++
+	}
+	return n, sc.Err()
+}
@@ -0,0 +1,41 @@
+package replay
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+)
+
+// BenchmarkHandler compares handling records through a handler holding
+// attrs from WithAttrs, encoded once, with passing them on every record.
+func BenchmarkHandler(b *testing.B) {
+	attrs := []slog.Attr{
+		slog.String("service", "orders"),
+		slog.String("region", "eu-west-1"),
+		slog.Int("pid", 4242),
+		slog.Group("build", slog.String("version", "1.2.3"), slog.String("commit", "abcdef0")),
+	}
+	ctx := context.Background()
+	h := NewHandler(io.Discard)
+
+	b.Run("WithAttrs", func(b *testing.B) {
+		h := h.WithAttrs(attrs)
+		r := slog.NewRecord(time.Now(), slog.LevelInfo, "order placed", 0)
+		r.AddAttrs(slog.String("id", "a1b2c3"))
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_ = h.Handle(ctx, r)
+		}
+	})
+	b.Run("RecordAttrs", func(b *testing.B) {
+		r := slog.NewRecord(time.Now(), slog.LevelInfo, "order placed", 0)
+		r.AddAttrs(attrs...)
+		r.AddAttrs(slog.String("id", "a1b2c3"))
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_ = h.Handle(ctx, r)
+		}
+	})
+}
@@ -0,0 +1,297 @@
+package logger
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+type RetryOption func(*retryOptions)
+
+type retryOptions struct {
+	attempts   int
+	backoff    time.Duration
+	maxBackoff time.Duration
+	maxAge     time.Duration
+	queueSize  int
+}
+
+// WithRetryAttempts sets how many times a record is handled, including the
+// first attempt, before it is dropped. Default: 5.
+func WithRetryAttempts(n int) RetryOption {
+	return func(opts *retryOptions) {
+		opts.attempts = n
+	}
+}
+
+// WithRetryBackoff sets the base delay of the jittered exponential backoff
+// between attempts and its cap. Default: 100ms and 10s.
+func WithRetryBackoff(backoff, maxBackoff time.Duration) RetryOption {
+	return func(opts *retryOptions) {
+		opts.backoff = backoff
+		opts.maxBackoff = maxBackoff
+	}
+}
+
+// WithRetryMaxAge drops records not handled within d of their first
+// failure, however many attempts are left. Default: 1m.
+func WithRetryMaxAge(d time.Duration) RetryOption {
+	return func(opts *retryOptions) {
+		opts.maxAge = d
+	}
+}
+
+// WithRetryQueue sets how many records wait for a retry before new ones are
+// dropped. Default: 1024.
+func WithRetryQueue(size int) RetryOption {
+	return func(opts *retryOptions) {
+		opts.queueSize = size
+	}
+}
+
+func RetryOptions(options ...RetryOption) *retryOptions {
+	opts := &retryOptions{
+		attempts:   5,
+		backoff:    100 * time.Millisecond,
+		maxBackoff: 10 * time.Second,
+		maxAge:     time.Minute,
+		queueSize:  1024,
+	}
+	for _, opt := range options {
+		opt(opts)
+	}
+	opts.attempts = max(opts.attempts, 1)
+	opts.backoff = max(opts.backoff, 0)
+	opts.maxBackoff = max(opts.maxBackoff, opts.backoff)
+	return opts
+}
+
+// delay returns a random delay up to backoff, and the backoff of the next
+// attempt.
+func (opts *retryOptions) delay(backoff time.Duration) (time.Duration, time.Duration) {
+	d := time.Duration(rand.Int63n(int64(backoff) + 1))
+	return d, min(backoff*2, opts.maxBackoff)
+}
+
+// RetryHandler retries records a sink, e.g. a network handler, failed on
+// in the background with jittered exponential backoff, until they succeed
+// or run out of attempts or age. While records wait for a retry new ones
+// queue up behind them, keeping their order. Flush retries the flush of a
+// sink implementing Flusher the same way. Close and CloseContext cut the
+// backoff short.
+type RetryHandler struct {
+	slog.Handler
+	core *retryCore
+}
+
+type retryCore struct {
+	opts *retryOptions
+
+	retried atomic.Uint64
+	dropped atomic.Uint64
+	pending atomic.Int64
+
+	mu       sync.RWMutex
+	closed   bool
+	once     sync.Once
+	queue    chan retryItem
+	done     chan struct{}
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+type retryItem struct {
+	ctx      context.Context
+	h        slog.Handler
+	r        slog.Record
+	first    time.Time
+	attempts int
+}
+
+// h := logger.NewRetryHandler(lokiHandler, logger.WithRetryMaxAge(30*time.Second))
+// defer h.Close()
+func NewRetryHandler(h slog.Handler, options ...RetryOption) *RetryHandler {
+	return &RetryHandler{Handler: h, core: &retryCore{opts: RetryOptions(options...), stop: make(chan struct{})}}
+}
+
+// Retried returns the number of retries of records and flushes.
+func (h *RetryHandler) Retried() uint64 {
+	return h.core.retried.Load()
+}
+
+// Dropped returns the number of records given up on.
+func (h *RetryHandler) Dropped() uint64 {
+	return h.core.dropped.Load()
+}
+
+// QueueDepth returns the number of records waiting for a retry.
+func (h *RetryHandler) QueueDepth() int {
+	return int(h.core.pending.Load())
+}
+
+func (h *RetryHandler) Handle(ctx context.Context, r slog.Record) error {
+	c := h.core
+	if c.pending.Load() > 0 {
+		c.enqueue(retryItem{ctx: ctx, h: h.Handler, r: r.Clone(), first: time.Now()})
+		return nil
+	}
+	if err := h.Handler.Handle(ctx, r); err == nil {
+		return nil
+	}
+	c.enqueue(retryItem{ctx: ctx, h: h.Handler, r: r.Clone(), first: time.Now(), attempts: 1})
+	return nil
+}
+
+func (h *RetryHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &RetryHandler{Handler: h.Handler.WithAttrs(attrs), core: h.core}
+}
+
+func (h *RetryHandler) WithGroup(name string) slog.Handler {
+	return &RetryHandler{Handler: h.Handler.WithGroup(name), core: h.core}
+}
+
+// Flush flushes the sink if it is a Flusher, retrying a failed flush.
+func (h *RetryHandler) Flush() error {
+	f, ok := h.Handler.(Flusher)
+	if !ok {
+		return nil
+	}
+
+	opts := h.core.opts
+	first := time.Now()
+	backoff := opts.backoff
+	for attempt := 1; ; attempt++ {
+		err := f.Flush()
+		if err == nil || attempt >= opts.attempts || time.Since(first) >= opts.maxAge {
+			return err
+		}
+		var d time.Duration
+		d, backoff = opts.delay(backoff)
+		if !h.core.wait(d) {
+			return err
+		}
+		h.core.retried.Add(1)
+	}
+}
+
+// Close gives the queued records a last attempt without waiting, then
+// flushes and closes the sink if it is an io.Closer. Records handled after
+// Close are not retried.
+func (h *RetryHandler) Close() error {
+	h.core.halt()
+	return h.CloseContext(context.Background())
+}
+
+// CloseContext keeps retrying the queued records until they are handled or
+// ctx is done, then closes as Close does.
+//
+//	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+//	defer cancel()
+//	_ = h.CloseContext(ctx)
+func (h *RetryHandler) CloseContext(ctx context.Context) error {
+	c := h.core
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		return nil
+	}
+	c.closed = true
+	if c.queue != nil {
+		close(c.queue)
+	}
+	c.mu.Unlock()
+
+	if c.done != nil {
+		select {
+		case <-c.done:
+		case <-ctx.Done():
+			c.halt()
+			<-c.done
+		}
+	}
+	c.halt()
+	err := h.Flush()
+	if cl, ok := h.Handler.(io.Closer); ok {
+		err = errors.Join(err, cl.Close())
+	}
+	return err
+}
+
+// halt cuts the backoff of retries short.
+func (c *retryCore) halt() {
+	c.stopOnce.Do(func() { close(c.stop) })
+}
+
+// wait waits for d and reports whether the handler was not halted meanwhile.
+func (c *retryCore) wait(d time.Duration) bool {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return true
+	case <-c.stop:
+		return false
+	}
+}
+
+func (c *retryCore) enqueue(item retryItem) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if c.closed {
+		c.dropped.Add(1)
+		return
+	}
+	c.once.Do(func() {
+		c.queue = make(chan retryItem, c.opts.queueSize)
+		c.done = make(chan struct{})
+		go func() {
+			defer close(c.done)
+			for item := range c.queue {
+				c.retry(item)
+				c.pending.Add(-1)
+			}
+		}()
+	})
+
+	item.ctx = context.WithoutCancel(item.ctx)
+	c.pending.Add(1)
+	select {
+	case c.queue <- item:
+	default:
+		c.pending.Add(-1)
+		c.dropped.Add(1)
+	}
+}
+
+// retry handles item until it succeeds or runs out of budget. Once halted
+// it makes a last attempt without waiting.
+func (c *retryCore) retry(item retryItem) {
+	backoff := c.opts.backoff
+	for attempt := item.attempts; ; attempt++ {
+		if attempt >= c.opts.attempts || time.Since(item.first) >= c.opts.maxAge {
+			c.dropped.Add(1)
+			return
+		}
+		if attempt > 0 {
+			var d time.Duration
+			d, backoff = c.opts.delay(backoff)
+			halted := !c.wait(d)
+			c.retried.Add(1)
+			if halted {
+				if item.h.Handle(item.ctx, item.r) != nil {
+					c.dropped.Add(1)
+				}
+				return
+			}
+		}
+		if item.h.Handle(item.ctx, item.r) == nil {
+			return
+		}
+	}
+}
@@ -0,0 +1,185 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"maps"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RingRecord is a record kept by RingHandler, with group members flattened
+// to dotted keys.
+type RingRecord struct {
+	Time    time.Time      `json:"time"`
+	Level   string         `json:"level"`
+	Message string         `json:"msg"`
+	Attrs   map[string]any `json:"attrs,omitempty"`
+}
+
+// RingHandler keeps the last size records in memory. Attrs from WithAttrs
+// are flattened once, not for every record.
+type RingHandler struct {
+	slog.Handler
+	ring   *recordRing
+	base   map[string]any
+	prefix string
+}
+
+type recordRing struct {
+	mu      sync.Mutex
+	records []RingRecord
+	next    int
+	full    bool
+}
+
+// ring := logger.NewRingHandler(h, 1000)
+func NewRingHandler(h slog.Handler, size int) *RingHandler {
+	return &RingHandler{Handler: h, ring: &recordRing{records: make([]RingRecord, max(size, 1))}}
+}
+
+// Records returns the kept records, oldest first.
+func (h *RingHandler) Records() []RingRecord {
+	rr := h.ring
+	rr.mu.Lock()
+	defer rr.mu.Unlock()
+
+	if !rr.full {
+		return append([]RingRecord(nil), rr.records[:rr.next]...)
+	}
+	out := make([]RingRecord, 0, len(rr.records))
+	out = append(out, rr.records[rr.next:]...)
+	return append(out, rr.records[:rr.next]...)
+}
+
+func (h *RingHandler) Handle(ctx context.Context, r slog.Record) error {
+	rec := RingRecord{Time: r.Time, Level: r.Level.String(), Message: r.Message}
+	if len(h.base) > 0 || r.NumAttrs() > 0 {
+		rec.Attrs = make(map[string]any, len(h.base)+r.NumAttrs())
+		maps.Copy(rec.Attrs, h.base)
+		r.Attrs(func(a slog.Attr) bool {
+			flattenAttr(rec.Attrs, h.prefix, a)
+			return true
+		})
+	}
+
+	rr := h.ring
+	rr.mu.Lock()
+	rr.records[rr.next] = rec
+	rr.next = (rr.next + 1) % len(rr.records)
+	if rr.next == 0 {
+		rr.full = true
+	}
+	rr.mu.Unlock()
+
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h *RingHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	nh := *h
+	nh.Handler = h.Handler.WithAttrs(attrs)
+	nh.base = maps.Clone(h.base)
+	if nh.base == nil {
+		nh.base = make(map[string]any, len(attrs))
+	}
+	for _, a := range attrs {
+		flattenAttr(nh.base, h.prefix, a)
+	}
+	return &nh
+}
+
+func (h *RingHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	nh := *h
+	nh.Handler = h.Handler.WithGroup(name)
+	nh.prefix = h.prefix + name + "."
+	return &nh
+}
+
+func flattenAttr(m map[string]any, prefix string, a slog.Attr) {
+	a.Value = a.Value.Resolve()
+	if a.Value.Kind() == slog.KindGroup {
+		if a.Key != "" {
+			prefix += a.Key + "."
+		}
+		for _, ga := range a.Value.Group() {
+			flattenAttr(m, prefix, ga)
+		}
+		return
+	}
+	if a.Key == "" {
+		return
+	}
+	v := a.Value.Any()
+	if err, ok := v.(error); ok {
+		v = err.Error()
+	}
+	m[strings.Clone(prefix+a.Key)] = v
+}
+
+// RingQuery selects kept records. Zero fields match every record.
+type RingQuery struct {
+	// Level is the minimum level.
+	Level *slog.Level
+	// Attrs must all equal the formatted values of the dotted attr keys.
+	Attrs map[string]string
+	// Text must be a substring of the message or of an attr value.
+	Text string
+	// Since is the oldest time.
+	Since time.Time
+	// Limit keeps only the newest Limit matches.
+	Limit int
+}
+
+// Match reports whether rec is selected by q.
+func (q RingQuery) Match(rec RingRecord) bool {
+	if q.Level != nil {
+		var level slog.Level
+		if err := level.UnmarshalText([]byte(rec.Level)); err != nil || level < *q.Level {
+			return false
+		}
+	}
+	if !q.Since.IsZero() && rec.Time.Before(q.Since) {
+		return false
+	}
+	for k, want := range q.Attrs {
+		v, ok := rec.Attrs[k]
+		if !ok || fmt.Sprint(v) != want {
+			return false
+		}
+	}
+	if q.Text == "" || strings.Contains(rec.Message, q.Text) {
+		return true
+	}
+	for _, v := range rec.Attrs {
+		if strings.Contains(fmt.Sprint(v), q.Text) {
+			return true
+		}
+	}
+	return false
+}
+
+// Filter returns the records selected by q, oldest first.
+func (q RingQuery) Filter(records []RingRecord) []RingRecord {
+	out := records[:0:0]
+	for _, rec := range records {
+		if q.Match(rec) {
+			out = append(out, rec)
+		}
+	}
+	if q.Limit > 0 && len(out) > q.Limit {
+		out = out[len(out)-q.Limit:]
+	}
+	return out
+}
+
+// Search returns the kept records selected by q, oldest first.
+//
+//	ring.Search(logger.RingQuery{Attrs: map[string]string{"request_id": id}})
+func (h *RingHandler) Search(q RingQuery) []RingRecord {
+	return q.Filter(h.Records())
+}
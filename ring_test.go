@@ -0,0 +1,45 @@
+package logger
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+)
+
+var benchAttrs = []slog.Attr{
+	slog.String("service", "orders"),
+	slog.String("region", "eu-west-1"),
+	slog.Int("pid", 4242),
+	slog.Group("build", slog.String("version", "1.2.3"), slog.String("commit", "abcdef0")),
+}
+
+// benchmarkWithAttrs compares handling records through a handler holding
+// benchAttrs from WithAttrs, encoded once, with passing them on every
+// record.
+func benchmarkWithAttrs(b *testing.B, h slog.Handler) {
+	ctx := context.Background()
+	b.Run("WithAttrs", func(b *testing.B) {
+		h := h.WithAttrs(benchAttrs)
+		r := slog.NewRecord(time.Now(), slog.LevelInfo, "order placed", 0)
+		r.AddAttrs(slog.String("id", "a1b2c3"))
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_ = h.Handle(ctx, r)
+		}
+	})
+	b.Run("RecordAttrs", func(b *testing.B) {
+		r := slog.NewRecord(time.Now(), slog.LevelInfo, "order placed", 0)
+		r.AddAttrs(benchAttrs...)
+		r.AddAttrs(slog.String("id", "a1b2c3"))
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_ = h.Handle(ctx, r)
+		}
+	})
+}
+
+func BenchmarkRingHandler(b *testing.B) {
+	benchmarkWithAttrs(b, NewRingHandler(slog.NewTextHandler(io.Discard, nil), 1024))
+}
@@ -0,0 +1,155 @@
+package logger
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+type SamplingOption func(*samplingOptions)
+
+type samplingOptions struct {
+	tick       time.Duration
+	first      uint64
+	thereafter uint64
+	traced     func(ctx context.Context) bool
+}
+
+// WithSampling keeps the first records per level and message every tick,
+// then every thereafter-th one (none when thereafter is 0).
+func WithSampling(tick time.Duration, first, thereafter uint64) SamplingOption {
+	return func(opts *samplingOptions) {
+		opts.tick = tick
+		opts.first = first
+		opts.thereafter = thereafter
+	}
+}
+
+// WithTraceAware always keeps records whose context carries a sampled trace
+// according to sampled, so logs and traces agree on the sampled subset.
+// logger.NewSamplingHandler(h, logger.WithTraceAware(oteltrace.Sampled))
+func WithTraceAware(sampled func(ctx context.Context) bool) SamplingOption {
+	return func(opts *samplingOptions) {
+		opts.traced = sampled
+	}
+}
+
+func SamplingOptions(options ...SamplingOption) *samplingOptions {
+	opts := &samplingOptions{
+		tick:       time.Second,
+		first:      100,
+		thereafter: 100,
+	}
+	for _, opt := range options {
+		opt(opts)
+	}
+	return opts
+}
+
+// SamplingHandler drops repetitive records. Records logged with NoSample or
+// a "force"=true attr are always kept.
+type SamplingHandler struct {
+	slog.Handler
+	sampler *sampler
+	level   slog.Leveler
+}
+
+// logger.NewSamplingHandler(h, logger.WithSampling(time.Second, 10, 100))
+func NewSamplingHandler(h slog.Handler, options ...SamplingOption) *SamplingHandler {
+	opts := SamplingOptions(options...)
+	return &SamplingHandler{
+		Handler: h,
+		sampler: &sampler{opts: opts, counters: make(map[samplingKey]*samplingCounter)},
+		level:   HandlerLevel(h),
+	}
+}
+
+// Enabled answers from the level of the wrapped handler, when it is
+// Leveled.
+func (h *SamplingHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	if h.level != nil {
+		return level >= h.level.Level()
+	}
+	return h.Handler.Enabled(ctx, level)
+}
+
+func (h *SamplingHandler) Handle(ctx context.Context, r slog.Record) error {
+	if forced(ctx, r) || (h.sampler.opts.traced != nil && h.sampler.opts.traced(ctx)) {
+		return h.Handler.Handle(ctx, r)
+	}
+	if !h.sampler.allow(r) {
+		return nil
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h *SamplingHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &SamplingHandler{Handler: h.Handler.WithAttrs(attrs), sampler: h.sampler, level: h.level}
+}
+
+func (h *SamplingHandler) WithGroup(name string) slog.Handler {
+	return &SamplingHandler{Handler: h.Handler.WithGroup(name), sampler: h.sampler, level: h.level}
+}
+
+// maxSamplingKeys bounds the counters kept for distinct level and message
+// pairs.
+const maxSamplingKeys = 4096
+
+// sampler is shared by a SamplingHandler and all handlers derived from it;
+// its counters are only accessed under mu.
+type sampler struct {
+	opts *samplingOptions
+
+	mu       sync.Mutex
+	counters map[samplingKey]*samplingCounter
+}
+
+type samplingKey struct {
+	level slog.Level
+	msg   string
+}
+
+type samplingCounter struct {
+	reset time.Time
+	n     uint64
+}
+
+func (s *sampler) allow(r slog.Record) bool {
+	now := r.Time
+	if now.IsZero() {
+		now = time.Now()
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := samplingKey{level: r.Level, msg: r.Message}
+	c, ok := s.counters[key]
+	if !ok || now.Sub(c.reset) >= s.opts.tick {
+		if !ok && len(s.counters) >= maxSamplingKeys {
+			s.evict(now)
+		}
+		c = &samplingCounter{reset: now}
+		s.counters[key] = c
+	}
+
+	c.n++
+	if c.n <= s.opts.first {
+		return true
+	}
+	return s.opts.thereafter > 0 && (c.n-s.opts.first)%s.opts.thereafter == 0
+}
+
+// evict drops the counters whose tick has elapsed, or all of them if every
+// counter is still live.
+func (s *sampler) evict(now time.Time) {
+	for k, c := range s.counters {
+		if now.Sub(c.reset) >= s.opts.tick {
+			delete(s.counters, k)
+		}
+	}
+	if len(s.counters) >= maxSamplingKeys {
+		clear(s.counters)
+	}
+}
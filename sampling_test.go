@@ -0,0 +1,46 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// countHandler counts the records it handles, across derived handlers.
+type countHandler struct {
+	n *atomic.Int64
+}
+
+func (h countHandler) Enabled(context.Context, slog.Level) bool  { return true }
+func (h countHandler) Handle(context.Context, slog.Record) error { h.n.Add(1); return nil }
+func (h countHandler) WithAttrs([]slog.Attr) slog.Handler        { return h }
+func (h countHandler) WithGroup(string) slog.Handler             { return h }
+
+func TestSamplingHandlerWithAttrsConcurrent(t *testing.T) {
+	var n atomic.Int64
+	h := NewSamplingHandler(countHandler{&n}, WithSampling(time.Hour, 10, 0))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			l := slog.New(h.WithAttrs([]slog.Attr{slog.Int("worker", i)}))
+			for j := 0; j < 400; j++ {
+				l.Info("repeated")
+				l.Info(fmt.Sprint("distinct ", i, j))
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	// derived handlers share the counters: "repeated" is kept 10 times in
+	// all, the distinct messages once each
+	if got, want := n.Load(), int64(10+8*400); got != want {
+		t.Errorf("handled %d records, want %d", got, want)
+	}
+}
@@ -0,0 +1,164 @@
+package logger
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+)
+
+const (
+	SchemaVersionKey string = "schema_version"
+	SchemaKey        string = "service.schema"
+)
+
+// RecordSchema is the record schema stamped by SchemaHandler: a name, a
+// version and per-message version overrides. It is safe for concurrent use.
+type RecordSchema struct {
+	mu       sync.RWMutex
+	name     string
+	version  int
+	messages map[string]int
+}
+
+// s := logger.NewRecordSchema("orders", 2)
+// logger.NewLogger(os.Stdout, logger.WithRecordSchema(s))
+func NewRecordSchema(name string, version int) *RecordSchema {
+	return &RecordSchema{name: name, version: version}
+}
+
+// Set sets the schema name and version.
+func (s *RecordSchema) Set(name string, version int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.name, s.version = name, version
+}
+
+// Bump increments the schema version, e.g. when a field changes meaning,
+// and returns the new version.
+func (s *RecordSchema) Bump() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.version++
+	return s.version
+}
+
+// SetMessageVersion overrides the version stamped on records with message
+// msg, so a single event can migrate ahead of the others.
+//
+//	s.SetMessageVersion("order placed", 3) // amount is now in cents
+func (s *RecordSchema) SetMessageVersion(msg string, version int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.messages == nil {
+		s.messages = make(map[string]int)
+	}
+	s.messages[msg] = version
+}
+
+// Version returns the schema name and version.
+func (s *RecordSchema) Version() (name string, version int) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.name, s.version
+}
+
+func (s *RecordSchema) of(msg string) (string, int) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if v, ok := s.messages[msg]; ok {
+		return s.name, v
+	}
+	return s.name, s.version
+}
+
+// WithSchema stamps every record with schema_version and service.schema
+// set to name and version. Use WithRecordSchema to change them later.
+func WithSchema(name string, version int) Option {
+	return WithRecordSchema(NewRecordSchema(name, version))
+}
+
+// WithRecordSchema stamps every record with the current version and name of
+// s.
+func WithRecordSchema(s *RecordSchema) Option {
+	return func(opts *loggerOptions) {
+		opts.schema = s
+	}
+}
+
+// SchemaHandler stamps records with the version and name of a schema, so
+// downstream consumers can handle migrations of field semantics. The stamp
+// is written at the top level, outside the groups opened by WithGroup.
+type SchemaHandler struct {
+	handler slog.Handler
+	schema  *RecordSchema
+	groups  []schemaGroup
+}
+
+// schemaGroup is a group opened by WithGroup with the attrs added in it.
+type schemaGroup struct {
+	name  string
+	attrs []slog.Attr
+}
+
+func NewSchemaHandler(h slog.Handler, s *RecordSchema) *SchemaHandler {
+	return &SchemaHandler{handler: h, schema: s}
+}
+
+func (h *SchemaHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.handler.Enabled(ctx, level)
+}
+
+func (h *SchemaHandler) Handle(ctx context.Context, r slog.Record) error {
+	name, version := h.schema.of(r.Message)
+	stamp := []slog.Attr{slog.Int(SchemaVersionKey, version)}
+	if name != "" {
+		stamp = append(stamp, slog.String(SchemaKey, name))
+	}
+	if len(h.groups) == 0 {
+		r.AddAttrs(stamp...)
+		return h.handler.Handle(ctx, r)
+	}
+
+	attrs := make([]slog.Attr, 0, r.NumAttrs())
+	r.Attrs(func(a slog.Attr) bool {
+		attrs = append(attrs, a)
+		return true
+	})
+	for i := len(h.groups) - 1; i >= 0; i-- {
+		g := h.groups[i]
+		attrs = append(g.attrs[:len(g.attrs):len(g.attrs)], attrs...)
+		if len(attrs) > 0 {
+			attrs = []slog.Attr{{Key: g.name, Value: slog.GroupValue(attrs...)}}
+		}
+	}
+	nr := slog.NewRecord(r.Time, r.Level, r.Message, r.PC)
+	nr.AddAttrs(attrs...)
+	nr.AddAttrs(stamp...)
+	return h.handler.Handle(ctx, nr)
+}
+
+func (h *SchemaHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+	if len(h.groups) == 0 {
+		return &SchemaHandler{handler: h.handler.WithAttrs(attrs), schema: h.schema}
+	}
+	groups := append([]schemaGroup(nil), h.groups...)
+	last := &groups[len(groups)-1]
+	last.attrs = append(last.attrs[:len(last.attrs):len(last.attrs)], attrs...)
+	return &SchemaHandler{handler: h.handler, schema: h.schema, groups: groups}
+}
+
+func (h *SchemaHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	groups := append(h.groups[:len(h.groups):len(h.groups)], schemaGroup{name: name})
+	return &SchemaHandler{handler: h.handler, schema: h.schema, groups: groups}
+}
@@ -0,0 +1,35 @@
+package logger
+
+import (
+	"context"
+	"log/slog"
+	"sync/atomic"
+)
+
+const SeqKey string = "seq"
+
+// SeqHandler stamps records with a monotonically increasing seq attr as
+// they are handled, before any async or batching handler below it, so
+// backends can detect and re-sort records delivered out of order.
+type SeqHandler struct {
+	slog.Handler
+	seq *atomic.Uint64
+}
+
+// logger.NewSeqHandler(logger.NewLatencyGuardHandler(h, 5*time.Millisecond))
+func NewSeqHandler(h slog.Handler) *SeqHandler {
+	return &SeqHandler{Handler: h, seq: new(atomic.Uint64)}
+}
+
+func (h *SeqHandler) Handle(ctx context.Context, r slog.Record) error {
+	r.AddAttrs(slog.Uint64(SeqKey, h.seq.Add(1)))
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h *SeqHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &SeqHandler{Handler: h.Handler.WithAttrs(attrs), seq: h.seq}
+}
+
+func (h *SeqHandler) WithGroup(name string) slog.Handler {
+	return &SeqHandler{Handler: h.Handler.WithGroup(name), seq: h.seq}
+}
@@ -0,0 +1,251 @@
+//go:build !logger_lite && !logger_nofile
+
+package logger
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// SessionKey is the default key of the attr naming the session or job of a
+// record for SessionFileHandler.
+const SessionKey string = "session_id"
+
+type SessionFileOption func(*sessionFileOptions)
+
+type sessionFileOptions struct {
+	key        string
+	dir        string
+	maxOpen    int
+	newHandler func(io.Writer) slog.Handler
+}
+
+// WithSessionKey sets the key of the session attr. Default: SessionKey.
+func WithSessionKey(key string) SessionFileOption {
+	return func(opts *sessionFileOptions) {
+		opts.key = key
+	}
+}
+
+// WithSessionDir sets the directory of the session files. Default: ".".
+func WithSessionDir(dir string) SessionFileOption {
+	return func(opts *sessionFileOptions) {
+		opts.dir = dir
+	}
+}
+
+// WithMaxSessionFiles sets how many session files are kept open; the least
+// recently written one is closed to open another. Default: 16.
+func WithMaxSessionFiles(n int) SessionFileOption {
+	return func(opts *sessionFileOptions) {
+		opts.maxOpen = n
+	}
+}
+
+// WithSessionHandler sets the handler writing a session file.
+// Default: slog.NewJSONHandler(w, nil).
+func WithSessionHandler(fn func(io.Writer) slog.Handler) SessionFileOption {
+	return func(opts *sessionFileOptions) {
+		opts.newHandler = fn
+	}
+}
+
+func SessionFileOptions(options ...SessionFileOption) *sessionFileOptions {
+	opts := &sessionFileOptions{
+		key:     SessionKey,
+		dir:     ".",
+		maxOpen: 16,
+		newHandler: func(w io.Writer) slog.Handler {
+			return slog.NewJSONHandler(w, nil)
+		},
+	}
+	for _, opt := range options {
+		opt(opts)
+	}
+	opts.maxOpen = max(opts.maxOpen, 1)
+	return opts
+}
+
+// SessionFileHandler writes records to h and, when they carry a session
+// attr, given on the record or with WithAttrs, also to the file
+// <dir>/<session>.log, e.g. per-job logs a batch pipeline must deliver.
+// Session files are appended to, so a session whose file was closed to open
+// others continues it.
+type SessionFileHandler struct {
+	slog.Handler
+	core    *sessionCore
+	session string
+	wrap    []func(slog.Handler) slog.Handler
+
+	mu      sync.Mutex
+	derived map[string]derivedSink
+}
+
+type sessionCore struct {
+	opts *sessionFileOptions
+
+	mu    sync.Mutex
+	files map[string]*list.Element
+	lru   list.List
+	gen   uint64
+}
+
+type sessionFile struct {
+	id      string
+	f       *os.File
+	handler slog.Handler
+	gen     uint64
+}
+
+// logger.NewSessionFileHandler(h, logger.WithSessionDir("/var/log/jobs"))
+// slog.Default().With(logger.SessionKey, job.ID).Info("job started")
+func NewSessionFileHandler(h slog.Handler, options ...SessionFileOption) *SessionFileHandler {
+	return &SessionFileHandler{
+		Handler: h,
+		core:    &sessionCore{opts: SessionFileOptions(options...), files: make(map[string]*list.Element)},
+	}
+}
+
+func (h *SessionFileHandler) Handle(ctx context.Context, r slog.Record) error {
+	session := h.session
+	r.Attrs(func(a slog.Attr) bool {
+		if a.Key == h.core.opts.key {
+			session = a.Value.String()
+			return false
+		}
+		return true
+	})
+
+	err := h.Handler.Handle(ctx, r)
+	if session == "" {
+		return err
+	}
+	return errors.Join(err, h.handleSession(ctx, session, r))
+}
+
+// handleSession writes r to the file of session. The core is locked while
+// writing so the file cannot be closed under the record.
+func (h *SessionFileHandler) handleSession(ctx context.Context, session string, r slog.Record) error {
+	c := h.core
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	sf, err := c.open(session)
+	if err != nil {
+		return err
+	}
+	return h.handler(sf).Handle(ctx, r.Clone())
+}
+
+// handler returns the handler of sf with h's attrs and groups applied,
+// rebuilding it when the file was reopened.
+func (h *SessionFileHandler) handler(sf *sessionFile) slog.Handler {
+	if len(h.wrap) == 0 {
+		return sf.handler
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if d, ok := h.derived[sf.id]; ok && d.gen == sf.gen {
+		return d.handler
+	}
+	if h.derived == nil || len(h.derived) >= h.core.opts.maxOpen {
+		h.derived = make(map[string]derivedSink)
+	}
+	sh := sf.handler
+	for _, w := range h.wrap {
+		sh = w(sh)
+	}
+	h.derived[sf.id] = derivedSink{gen: sf.gen, handler: sh}
+	return sh
+}
+
+// open returns the open file of session, opening it and closing the least
+// recently used one if needed. c.mu must be held.
+func (c *sessionCore) open(session string) (*sessionFile, error) {
+	if e, ok := c.files[session]; ok {
+		c.lru.MoveToFront(e)
+		return e.Value.(*sessionFile), nil
+	}
+
+	for c.lru.Len() >= c.opts.maxOpen {
+		c.evict(c.lru.Back())
+	}
+	f, err := os.OpenFile(filepath.Join(c.opts.dir, sessionFileName(session)), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	c.gen++
+	sf := &sessionFile{id: session, f: f, handler: c.opts.newHandler(f), gen: c.gen}
+	c.files[session] = c.lru.PushFront(sf)
+	return sf, nil
+}
+
+func (c *sessionCore) evict(e *list.Element) error {
+	sf := c.lru.Remove(e).(*sessionFile)
+	delete(c.files, sf.id)
+	return sf.f.Close()
+}
+
+// sessionFileName keeps session IDs from escaping the session directory.
+func sessionFileName(session string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_', r == '.':
+			return r
+		}
+		return '_'
+	}, session) + ".log"
+}
+
+// CloseSession closes the file of session, e.g. when its job is done.
+func (h *SessionFileHandler) CloseSession(session string) error {
+	c := h.core
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if e, ok := c.files[session]; ok {
+		return c.evict(e)
+	}
+	return nil
+}
+
+// Close closes all session files. h keeps working and reopens them.
+func (h *SessionFileHandler) Close() error {
+	c := h.core
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var errs []error
+	for c.lru.Len() > 0 {
+		errs = append(errs, c.evict(c.lru.Back()))
+	}
+	return errors.Join(errs...)
+}
+
+func (h *SessionFileHandler) derive(handler slog.Handler, session string, w func(slog.Handler) slog.Handler) *SessionFileHandler {
+	wrap := append(h.wrap[:len(h.wrap):len(h.wrap)], w)
+	return &SessionFileHandler{Handler: handler, core: h.core, session: session, wrap: wrap}
+}
+
+func (h *SessionFileHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	session := h.session
+	for _, a := range attrs {
+		if a.Key == h.core.opts.key {
+			session = a.Value.String()
+		}
+	}
+	return h.derive(h.Handler.WithAttrs(attrs), session, func(sh slog.Handler) slog.Handler { return sh.WithAttrs(attrs) })
+}
+
+func (h *SessionFileHandler) WithGroup(name string) slog.Handler {
+	return h.derive(h.Handler.WithGroup(name), h.session, func(sh slog.Handler) slog.Handler { return sh.WithGroup(name) })
+}
@@ -0,0 +1,106 @@
+package logger
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"reflect"
+	"sync"
+)
+
+// Flusher is implemented by handlers and writers that buffer output, like
+// FileWriter.
+type Flusher interface {
+	Flush() error
+}
+
+var closers struct {
+	mu   sync.Mutex
+	list []io.Closer
+}
+
+// RegisterCloser registers c to be flushed, if it is a Flusher, by Flush
+// and closed by Shutdown, e.g. an EncodePoolHandler or a network sink.
+// Builder.BuildLogger with AsDefault and Flags.Build register their
+// handler, until it is closed.
+func RegisterCloser(c io.Closer) {
+	closers.mu.Lock()
+	defer closers.mu.Unlock()
+
+	if reflect.TypeOf(c).Comparable() {
+		for _, r := range closers.list {
+			if reflect.TypeOf(r).Comparable() && r == c {
+				return
+			}
+		}
+	}
+	closers.list = append(closers.list, c)
+}
+
+// unregisterCloser removes c from the registered closers.
+func unregisterCloser(c io.Closer) {
+	closers.mu.Lock()
+	defer closers.mu.Unlock()
+
+	for i, r := range closers.list {
+		if reflect.TypeOf(r).Comparable() && r == c {
+			closers.list = append(closers.list[:i:i], closers.list[i+1:]...)
+			return
+		}
+	}
+}
+
+// Flush flushes the registered Flushers and syncs the registered files.
+func Flush() error {
+	closers.mu.Lock()
+	list := closers.list
+	closers.mu.Unlock()
+
+	var errs []error
+	for _, c := range list {
+		errs = append(errs, flush(c))
+	}
+	return errors.Join(errs...)
+}
+
+func flush(c any) error {
+	switch c := c.(type) {
+	case Flusher:
+		return c.Flush()
+	case *os.File:
+		return c.Sync()
+	}
+	return nil
+}
+
+// Shutdown runs the exit hooks, then flushes and closes the registered
+// closers, the last registered first, and unregisters them. It returns
+// ctx.Err() if ctx is done first; the closing goes on in the background.
+//
+//	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+//	defer cancel()
+//	_ = logger.Shutdown(ctx)
+func Shutdown(ctx context.Context) error {
+	closers.mu.Lock()
+	list := closers.list
+	closers.list = nil
+	closers.mu.Unlock()
+
+	done := make(chan error, 1)
+	go func() {
+		RunExitHooks()
+		var errs []error
+		for i := len(list) - 1; i >= 0; i-- {
+			errs = append(errs, flush(list[i]), list[i].Close())
+		}
+		done <- errors.Join(errs...)
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,49 @@
+package logger
+
+import "log/slog"
+
+// Reopener is a writer that can reopen its file, like FileWriter.
+type Reopener interface {
+	Reopen() error
+}
+
+type SignalOption func(*signalOptions)
+
+type signalOptions struct {
+	reopen   []Reopener
+	levels   []string
+	levelVar *slog.LevelVar
+}
+
+// WithSignalReopen reopens ws on SIGHUP.
+func WithSignalReopen(ws ...Reopener) SignalOption {
+	return func(opts *signalOptions) {
+		opts.reopen = append(opts.reopen, ws...)
+	}
+}
+
+// WithSignalLevels sets the levels SIGUSR2 cycles through.
+// Default: DEBUG, INFO, WARN, ERROR.
+func WithSignalLevels(levels ...string) SignalOption {
+	return func(opts *signalOptions) {
+		opts.levels = levels
+	}
+}
+
+// WithSignalLevelVar makes SIGUSR1 and SIGUSR2 change lv, for handlers
+// built with WithLevelVar, instead of the package level.
+func WithSignalLevelVar(lv *slog.LevelVar) SignalOption {
+	return func(opts *signalOptions) {
+		opts.levelVar = lv
+	}
+}
+
+func SignalOptions(options ...SignalOption) *signalOptions {
+	opts := &signalOptions{
+		levels: []string{LevelDebug, LevelInfo, LevelWarn, LevelError},
+	}
+	for _, opt := range options {
+		opt(opts)
+	}
+	return opts
+}
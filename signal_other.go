@@ -0,0 +1,10 @@
+//go:build !unix || logger_lite
+
+package logger
+
+import "context"
+
+// HandleSignals is not supported on this platform or in lite builds.
+func HandleSignals(ctx context.Context, options ...SignalOption) error {
+	return ErrUnsupported
+}
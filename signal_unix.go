@@ -0,0 +1,98 @@
+//go:build unix && !logger_lite
+
+package logger
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// HandleSignals reopens the WithSignalReopen files on SIGHUP, for
+// logrotate, toggles the level between DEBUG and its previous value on
+// SIGUSR1, and moves it to the next WithSignalLevels level on SIGUSR2,
+// until ctx is done. The level is the package level followed by the
+// handlers of NewLogger and Builder, or the WithSignalLevelVar one.
+//
+//	logger.HandleSignals(ctx, logger.WithSignalReopen(file))
+//	kill -USR1 $(pidof app)
+func HandleSignals(ctx context.Context, options ...SignalOption) error {
+	opts := SignalOptions(options...)
+
+	c := make(chan os.Signal, 1)
+	signal.Notify(c, syscall.SIGHUP, syscall.SIGUSR1, syscall.SIGUSR2)
+	go func() {
+		defer signal.Stop(c)
+
+		previous := opts.level()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case sig := <-c:
+				switch sig {
+				case syscall.SIGHUP:
+					for _, w := range opts.reopen {
+						if err := w.Reopen(); err != nil {
+							slog.Default().Error("reopen log file", "err", err)
+						}
+					}
+				case syscall.SIGUSR1:
+					if level := opts.level(); level != LevelDebug {
+						previous = level
+						opts.signalLevel(sig, LevelDebug)
+					} else {
+						opts.signalLevel(sig, previous)
+					}
+				case syscall.SIGUSR2:
+					opts.signalLevel(sig, nextLevel(opts.levels, opts.level()))
+				}
+			}
+		}
+	}()
+	return nil
+}
+
+// level returns the level changed by the signals.
+func (opts *signalOptions) level() string {
+	if opts.levelVar != nil {
+		return levelName(opts.levelVar.Level())
+	}
+	return GetLevel()
+}
+
+func (opts *signalOptions) setLevel(level string) error {
+	if opts.levelVar == nil {
+		return SetLevel(level)
+	}
+	name := levelString(level)
+	if name == "" {
+		return fmt.Errorf("logger: unknown level %q", level)
+	}
+	opts.levelVar.Set(levelOf(name))
+	return nil
+}
+
+func (opts *signalOptions) signalLevel(sig os.Signal, level string) {
+	if err := opts.setLevel(level); err != nil {
+		slog.Default().Error("set log level", "signal", sig.String(), "err", err)
+		return
+	}
+	slog.Default().Log(context.Background(), levelOf(opts.level()), "log level changed", "level", opts.level(), "signal", sig.String())
+}
+
+// nextLevel returns the level after current in levels, wrapping around.
+func nextLevel(levels []string, current string) string {
+	if len(levels) == 0 {
+		return current
+	}
+	for i, level := range levels {
+		if levelString(level) == current {
+			return levels[(i+1)%len(levels)]
+		}
+	}
+	return levels[0]
+}
@@ -2,32 +2,48 @@ package logger
 
 import (
 	"context"
-	"fmt"
 	"io"
 	"log/slog"
-	"path/filepath"
 	"runtime"
-	"time"
 )
 
 // logger.NewLogger(os.Stdout, logger.WithJSON(true))
 // slog.Info("init", "logger", "log/slog", "format", "json")
 func NewLogger(w io.Writer, options ...Option) *slog.Logger {
 	opts := LoggerOptions(options...)
+	opts.globalLevel = true
+	opts.setGlobalLevel()
+	l := slog.New(newHandler(w, opts))
 
-	var level slog.Level
-	switch opts.level {
-	case LevelDebug:
-		level = slog.LevelDebug
-	case LevelInfo:
-		level = slog.LevelInfo
-	case LevelWarn:
-		level = slog.LevelWarn
-	case LevelError:
-		level = slog.LevelInfo
-	default:
-		level = slog.LevelInfo
+	slog.SetDefault(l)
+	currentConfig.Store(opts.config())
+	return l
+}
+
+// setGlobalLevel sets the package level to the WithLevel level of a
+// handler following it.
+func (opts *loggerOptions) setGlobalLevel() {
+	if opts.globalLevel && opts.levelVar == nil && opts.levelSet {
+		globalLevel.Set(levelOf(opts.level))
 	}
+}
+
+// handlerLevel returns the level a handler built with opts follows: the
+// WithLevelVar one, the package one or its own.
+func (opts *loggerOptions) handlerLevel() *slog.LevelVar {
+	switch {
+	case opts.levelVar != nil:
+		return opts.levelVar
+	case opts.globalLevel:
+		return &globalLevel
+	}
+	lv := &slog.LevelVar{}
+	lv.Set(levelOf(opts.level))
+	return lv
+}
+
+func newHandler(w io.Writer, opts *loggerOptions) ContextHandler {
+	level := opts.handlerLevel()
 
 	hOpts := &slog.HandlerOptions{
 		AddSource: false,
@@ -36,80 +52,235 @@ func NewLogger(w io.Writer, options ...Option) *slog.Logger {
 			if a.Key == slog.SourceKey {
 				if s, ok := a.Value.Any().(*slog.Source); ok {
 					if s != nil {
-						return slog.String("caller", fmt.Sprintf("%s/%s:%d", filepath.Base(filepath.Dir(s.File)), filepath.Base(s.File), s.Line))
+						return slog.String(opts.keys.Source, opts.source(s))
 					}
 				}
 			}
-			if a.Key == slog.TimeKey {
-				return slog.String("time", time.Now().Format(opts.timeFormat))
+			if a.Key == slog.TimeKey && len(groups) == 0 && a.Value.Kind() == slog.KindTime {
+				t := a.Value.Time()
+				if opts.epoch != "" {
+					return slog.Int64(opts.keys.Time, unixTime(t, opts.epoch))
+				}
+				if opts.location != nil {
+					t = t.In(opts.location)
+				}
+				return slog.String(opts.keys.Time, t.Format(opts.timeFormat))
 			}
 			if a.Key == slog.MessageKey {
 				if len(a.Value.String()) == 0 {
 					return slog.Attr{}
 				}
+				if len(groups) == 0 {
+					a.Key = opts.keys.Message
+				}
+			}
+			if a.Key == slog.LevelKey && len(groups) == 0 {
+				if level, ok := a.Value.Any().(slog.Level); ok {
+					return slog.String(opts.keys.Level, levelName(level))
+				}
+				a.Key = opts.keys.Level
 			}
 			return a
 		},
 	}
 
-	var h interface{}
-	if opts.json {
+	if len(opts.replaceAttr) > 0 {
+		hOpts.ReplaceAttr = ChainReplaceAttr(append(opts.replaceAttr[:len(opts.replaceAttr):len(opts.replaceAttr)], hOpts.ReplaceAttr)...)
+	}
+
+	var h slog.Handler
+	switch {
+	case opts.json:
 		h = slog.NewJSONHandler(w, hOpts)
-	} else {
+	case opts.fastText:
+		fh := NewFastTextHandler(w, level, opts.timeFormat)
+		fh.location = opts.location
+		fh.keys = opts.keys
+		fh.epoch = opts.epoch
+		fh.multiline = opts.multiline
+		if opts.levelStyles != nil {
+			fh.styles = levelStyles(opts.levelStyles, w)
+		}
+		h = fh
+	default:
 		h = slog.NewTextHandler(w, hOpts)
 	}
 
-	keys := []any{
-		sourceKey{}, 
+	if opts.attrOrder != nil {
+		h = &AttrOrderHandler{Handler: h, order: opts.attrOrder}
+	}
+	if len(opts.transforms) > 0 {
+		// the rules were validated by WithTransforms
+		h, _ = NewTransformHandler(h, opts.transforms...)
+	}
+	if opts.schema != nil {
+		h = NewSchemaHandler(h, opts.schema)
 	}
 
-	var l *slog.Logger
-	if opts.json {
-		enc := h.(*slog.JSONHandler)
-		h := ContextHandler{enc, keys}
-		l = slog.New(h)
-	} else {
-		enc := h.(*slog.TextHandler)
-		h := ContextHandler{enc, keys}
-		l = slog.New(h)
+	return opts.contextHandler(h, level)
+}
+
+// contextHandler wraps h in the ContextHandler of opts. A nil level makes
+// Enabled ask h.
+func (opts *loggerOptions) contextHandler(h slog.Handler, level slog.Leveler) ContextHandler {
+	keys := []any{
+		sourceKey{},
+		requestIDKey{},
+		traceKey{},
+		attrsKey{},
 	}
 
-	slog.SetDefault(l)
-	return l
+	return ContextHandler{
+		Handler:      h,
+		keys:         keys,
+		extractors:   newExtractorSet(opts.extractors),
+		skip:         opts.callerSkip,
+		fallbackSkip: opts.fallbackSkip,
+		level:        level,
+	}
 }
 
 type ContextHandler struct {
 	slog.Handler
-	keys []any
+	keys         []any
+	extractors   *extractorSet
+	skip         int
+	fallbackSkip int
+	level        slog.Leveler
+}
+
+// Enabled answers from the configured level, when known, instead of asking
+// every handler down the chain.
+func (h ContextHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	if h.level != nil {
+		return level >= h.level.Level()
+	}
+	return h.Handler.Enabled(ctx, level)
+}
+
+// Leveler returns the configured level, nil when Enabled asks the wrapped
+// handler.
+func (h ContextHandler) Leveler() slog.Leveler {
+	return h.level
 }
 
 func (h ContextHandler) Handle(ctx context.Context, r slog.Record) error {
 	if ctx.Value(sourceKey{}) == nil {
-		r.Add(slog.SourceKey, CallerSource(4))
+		skip, _ := ctx.Value(callerSkipKey{}).(int)
+		if r.PC != 0 {
+			r.Add(slog.SourceKey, pcSource(r.PC, h.skip+skip))
+		} else {
+			r.Add(slog.SourceKey, CallerSource(4+h.fallbackSkip+h.skip+skip))
+		}
 	}
 	r.AddAttrs(h.observe(ctx)...)
+	r.AddAttrs(h.extractors.extract(ctx)...)
 	return h.Handler.Handle(ctx, r)
 }
 
+func (h ContextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	h.Handler = h.Handler.WithAttrs(attrs)
+	return h
+}
+
+func (h ContextHandler) WithGroup(name string) slog.Handler {
+	h.Handler = h.Handler.WithGroup(name)
+	return h
+}
+
+// SkipCallers returns a logger reporting the caller n frames above the
+// default, for helpers that wrap *slog.Logger methods.
+//
+//	func logError(err error) { logger.SkipCallers(slog.Default(), 1).Error(err.Error()) }
+func SkipCallers(l *slog.Logger, n int) *slog.Logger {
+	h, ok := skipCallers(l.Handler(), n)
+	if !ok {
+		return l
+	}
+	return slog.New(h)
+}
+
+func skipCallers(h slog.Handler, n int) (slog.Handler, bool) {
+	switch h := h.(type) {
+	case ContextHandler:
+		h.skip += n
+		return h, true
+	case *BuiltHandler:
+		inner, ok := skipCallers(h.Handler, n)
+		if !ok {
+			return h, false
+		}
+		h2 := *h
+		h2.Handler = inner
+		return &h2, true
+	}
+	return h, false
+}
+
 func (h ContextHandler) observe(ctx context.Context) (as []slog.Attr) {
 	for _, k := range h.keys {
-		a, ok := ctx.Value(k).(slog.Attr)
-		if !ok {
-			continue
+		switch v := ctx.Value(k).(type) {
+		case slog.Attr:
+			v.Value = v.Value.Resolve()
+			as = append(as, v)
+		case []slog.Attr:
+			as = append(as, v...)
 		}
-		a.Value = a.Value.Resolve()
-		as = append(as, a)
 	}
 	return
 }
 
+// Enabled reports whether the default logger handles records at level, so
+// callers can skip building expensive attrs:
+//
+//	if logger.Enabled(ctx, slog.LevelDebug) {
+//		slog.DebugContext(ctx, "state", "dump", expensiveDump())
+//	}
+func Enabled(ctx context.Context, level slog.Level) bool {
+	return slog.Default().Enabled(ctx, level)
+}
+
+// SourceContext reports s as the source of records logged with the returned
+// context instead of the call site.
 func SourceContext(ctx context.Context, s *slog.Source) context.Context {
 	return context.WithValue(ctx, sourceKey{}, slog.Any(slog.SourceKey, s))
 }
 
+// CallerSource returns the source skip frames above its caller, as
+// runtime.Caller does.
 func CallerSource(skip int) *slog.Source {
-	_, file, line, _ := runtime.Caller(skip)
-	return &slog.Source{File: file, Line: line}
+	pc, file, line, _ := runtime.Caller(skip)
+	s := &slog.Source{File: file, Line: line}
+	if fn := runtime.FuncForPC(pc); fn != nil {
+		s.Function = fn.Name()
+	}
+	return s
+}
+
+// pcSource returns the source of the frame at pc, the logging call of a
+// record, or skip frames above it if pc is on the current stack. Unlike a
+// fixed stack depth this holds however many handlers wrap ContextHandler.
+func pcSource(pc uintptr, skip int) *slog.Source {
+	pcs := []uintptr{pc}
+	if skip > 0 {
+		var stack [64]uintptr
+		n := runtime.Callers(2, stack[:])
+		for i, p := range stack[:n] {
+			if p == pc {
+				pcs = stack[i:n]
+				break
+			}
+		}
+	}
+
+	frames := runtime.CallersFrames(pcs)
+	for {
+		f, more := frames.Next()
+		if skip == 0 || !more {
+			return &slog.Source{Function: f.Function, File: f.File, Line: f.Line}
+		}
+		skip--
+	}
 }
 
 type sourceKey struct{}
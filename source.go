@@ -0,0 +1,161 @@
+package logger
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+const (
+	SourceShort  string = "short"
+	SourceFull   string = "full"
+	SourceModule string = "module"
+)
+
+// WithSourcePath selects how the caller path is rendered: SourceShort
+// ("dir/file.go:line", the default), SourceFull (absolute path) or
+// SourceModule (relative to the module root, e.g. "cmd/logger/main.go:13",
+// or "gorm.io/gorm@v1.25.9/finisher_api.go:42" for dependencies).
+func WithSourcePath(mode string) Option {
+	return func(opts *loggerOptions) {
+		switch mode {
+		case SourceFull:
+			opts.sourceFunc = func(s *slog.Source) string {
+				return fmt.Sprintf("%s:%d", s.File, s.Line)
+			}
+		case SourceModule:
+			opts.sourceFunc = func(s *slog.Source) string {
+				return fmt.Sprintf("%s:%d", modulePath(s.File), s.Line)
+			}
+		default:
+			opts.sourceFunc = shortSource
+		}
+	}
+}
+
+// WithSourceTrim renders the full caller path without the first matching
+// prefix, e.g. a CI workspace or "$GOPATH/src/".
+func WithSourceTrim(prefixes ...string) Option {
+	return func(opts *loggerOptions) {
+		opts.sourceFunc = func(s *slog.Source) string {
+			file := s.File
+			for _, p := range prefixes {
+				if strings.HasPrefix(file, p) {
+					file = strings.TrimPrefix(file[len(p):], "/")
+					break
+				}
+			}
+			return fmt.Sprintf("%s:%d", file, s.Line)
+		}
+	}
+}
+
+func WithSourceFunc(fn func(s *slog.Source) string) Option {
+	return func(opts *loggerOptions) {
+		opts.sourceFunc = fn
+	}
+}
+
+const (
+	FunctionNone  string = ""
+	FunctionShort string = "short"
+	FunctionFull  string = "full"
+)
+
+// WithSourceFunction appends the calling function to the caller attr, as
+// "pkg.(*T).Method" with FunctionShort or with the full import path with
+// FunctionFull: caller="logger/main.go:13 main.main".
+func WithSourceFunction(mode string) Option {
+	return func(opts *loggerOptions) {
+		opts.sourceFunction = mode
+	}
+}
+
+func (opts *loggerOptions) source(s *slog.Source) string {
+	caller := opts.sourceFunc(s)
+	if s.Function == "" {
+		return caller
+	}
+	switch opts.sourceFunction {
+	case FunctionShort:
+		fn := s.Function
+		if i := strings.LastIndex(fn, "/"); i >= 0 {
+			fn = fn[i+1:]
+		}
+		return caller + " " + fn
+	case FunctionFull:
+		return caller + " " + s.Function
+	}
+	return caller
+}
+
+func shortSource(s *slog.Source) string {
+	return fmt.Sprintf("%s/%s:%d", filepath.Base(filepath.Dir(s.File)), filepath.Base(s.File), s.Line)
+}
+
+var (
+	goRootSrc = filepath.ToSlash(filepath.Join(runtime.GOROOT(), "src")) + "/"
+
+	moduleRoots sync.Map
+)
+
+// modulePath returns file relative to the root of the module containing it.
+func modulePath(file string) string {
+	file = filepath.ToSlash(file)
+	if strings.HasPrefix(file, goModDir) {
+		return strings.TrimPrefix(file, goModDir)
+	}
+	if strings.HasPrefix(file, goRootSrc) {
+		return strings.TrimPrefix(file, goRootSrc)
+	}
+	if !filepath.IsAbs(file) {
+		// built with -trimpath: already module-qualified
+		return file
+	}
+
+	dir := filepath.Dir(file)
+	root, ok := moduleRoots.Load(dir)
+	if !ok {
+		root = findModuleRoot(dir)
+		moduleRoots.Store(dir, root)
+	}
+	if r := root.(string); r != "" {
+		return strings.TrimPrefix(file, r+"/")
+	}
+	return file
+}
+
+func findModuleRoot(dir string) string {
+	for d := dir; ; d = filepath.Dir(d) {
+		if _, err := os.Stat(filepath.Join(d, "go.mod")); err == nil {
+			return filepath.ToSlash(d)
+		}
+		if filepath.Dir(d) == d {
+			return ""
+		}
+	}
+}
+
+// externalSource walks the stack from its caller and returns the first frame
+// for which internal reports false, then skips skip more frames, for
+// adapters called through a library's own wrappers.
+func externalSource(skip int, internal func(runtime.Frame) bool) *slog.Source {
+	pcs := [32]uintptr{}
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(2, pcs[:])])
+	for {
+		f, more := frames.Next()
+		if !internal(f) {
+			if skip == 0 || !more {
+				return &slog.Source{Function: f.Function, File: f.File, Line: f.Line}
+			}
+			skip--
+		}
+		if !more {
+			return &slog.Source{}
+		}
+	}
+}
@@ -0,0 +1,12 @@
+//go:build !logger_lite
+
+package logger
+
+import (
+	"go/build"
+	"path/filepath"
+)
+
+var goModDir = filepath.ToSlash(filepath.Join(build.Default.GOPATH, "pkg", "mod")) + "/"
+
+const liteBuild = false
@@ -0,0 +1,55 @@
+package logger
+
+import (
+	"context"
+	"log/slog"
+	"sync/atomic"
+	"time"
+)
+
+// SpanStarter starts a tracing span and returns the context carrying it and
+// a function ending it. oteltrace.UseTracer installs an OpenTelemetry one.
+type SpanStarter func(ctx context.Context, name string) (context.Context, func(err error))
+
+var spanStarter atomic.Pointer[SpanStarter]
+
+func SetSpanStarter(s SpanStarter) {
+	if s == nil {
+		spanStarter.Store(nil)
+		return
+	}
+	spanStarter.Store(&s)
+}
+
+// Span logs the start and the end of an operation with its duration and,
+// with a SpanStarter installed, traces it as a span of the same name.
+//
+//	ctx, end := logger.Span(ctx, "charge", "order_id", id)
+//	defer end(&err)
+func Span(ctx context.Context, name string, args ...any) (context.Context, func(errp *error)) {
+	begin := time.Now()
+
+	end := func(error) {}
+	if s := spanStarter.Load(); s != nil {
+		ctx, end = (*s)(ctx, name)
+	}
+
+	l := slog.Default().With(args...)
+	l.Log(SourceContext(ctx, CallerSource(2)), slog.LevelDebug, name+" started")
+
+	return ctx, func(errp *error) {
+		var err error
+		if errp != nil {
+			err = *errp
+		}
+		end(err)
+
+		sctx := SourceContext(ctx, CallerSource(2))
+		ms := slog.Float64("ms", float64(time.Since(begin).Nanoseconds())/1e6)
+		if err != nil {
+			l.LogAttrs(sctx, slog.LevelError, name+" failed", ms, slog.Any("err", err))
+			return
+		}
+		l.LogAttrs(sctx, slog.LevelInfo, name+" finished", ms)
+	}
+}
@@ -0,0 +1,475 @@
+// Package spool persists records to a local append-only file while a sink,
+// e.g. a network handler, is unavailable, and replays them in order once it
+// recovers, so edge deployments keep their logs across outages.
+//
+//	h, err := spool.New(remoteHandler, "/var/lib/app/logs.spool", spool.WithMaxBytes(256<<20))
+//	...
+//	defer h.Close()
+package spool
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"log/slog"
+	"os"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/isauran/logger/replay"
+)
+
+// ErrFull is returned for a record dropped because the spool file reached
+// its size cap.
+var ErrFull = errors.New("spool: full")
+
+type Option func(*spoolOptions)
+
+type spoolOptions struct {
+	maxBytes    int64
+	interval    time.Duration
+	maxAttempts int
+}
+
+// WithMaxBytes caps the spool file at n bytes, replayed records included
+// until they are compacted away; records that do not fit are dropped. Zero
+// means no cap. Default: 64 MiB.
+func WithMaxBytes(n int64) Option {
+	return func(opts *spoolOptions) {
+		opts.maxBytes = n
+	}
+}
+
+// WithReplayInterval sets how often spooled records are replayed to the
+// sink. Default: 5s.
+func WithReplayInterval(d time.Duration) Option {
+	return func(opts *spoolOptions) {
+		opts.interval = d
+	}
+}
+
+// WithMaxAttempts skips a record the sink failed on n times in a row once
+// the sink takes the record after it, so a record the sink rejects does
+// not block the spool. Default: 5.
+func WithMaxAttempts(n int) Option {
+	return func(opts *spoolOptions) {
+		opts.maxAttempts = n
+	}
+}
+
+func SpoolOptions(options ...Option) *spoolOptions {
+	opts := &spoolOptions{
+		maxBytes:    64 << 20,
+		interval:    5 * time.Second,
+		maxAttempts: 5,
+	}
+	for _, opt := range options {
+		opt(opts)
+	}
+	opts.maxAttempts = max(opts.maxAttempts, 1)
+	return opts
+}
+
+// compactAt returns how many replayed bytes the spool file keeps before
+// they are dropped from it.
+func (opts *spoolOptions) compactAt() int64 {
+	if opts.maxBytes > 0 {
+		return max(opts.maxBytes/4, 1)
+	}
+	return 16 << 20
+}
+
+// Stats reports the state of the spool.
+type Stats struct {
+	Bytes     int64  `json:"bytes"`
+	Spooled   uint64 `json:"spooled"`
+	Replayed  uint64 `json:"replayed"`
+	Dropped   uint64 `json:"dropped"`
+	Rejected  uint64 `json:"rejected"`
+	Corrupted uint64 `json:"corrupted"`
+}
+
+// Handler passes records to a sink and spools those the sink fails on.
+// While records are spooled new ones are spooled behind them, and every
+// replay interval they are replayed to the sink in order until the spool is
+// drained. The replay offset is saved next to the spool file, in
+// path.offset, after every record, so a restart resumes after the last
+// record replayed; records are replayed at least once. Replayed records are
+// dropped from the file once they take a quarter of WithMaxBytes.
+//
+// Each record is a line of a replay.Envelope prefixed with its CRC-32, so a
+// torn or corrupted record is skipped and counted without losing the rest.
+type Handler struct {
+	sink slog.Handler
+	enc  slog.Handler
+	core *core
+}
+
+type core struct {
+	opts *spoolOptions
+	sink slog.Handler
+	path string
+
+	mu     sync.Mutex
+	f      *os.File
+	off    *os.File
+	size   int64
+	offset int64
+
+	// failOffset is the offset of the record the sink failed on failures
+	// times in a row; only replay uses them.
+	failOffset int64
+	failures   int
+
+	replaying atomic.Bool
+	spooled   atomic.Uint64
+	replayed  atomic.Uint64
+	dropped   atomic.Uint64
+	rejected  atomic.Uint64
+	corrupted atomic.Uint64
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// New opens, or creates, the spool file at path and replays records left in
+// it by an earlier run.
+func New(sink slog.Handler, path string, options ...Option) (*Handler, error) {
+	c := &core{
+		opts:       SpoolOptions(options...),
+		sink:       sink,
+		path:       path,
+		failOffset: -1,
+		stop:       make(chan struct{}),
+		done:       make(chan struct{}),
+	}
+	if err := c.open(); err != nil {
+		return nil, fmt.Errorf("spool: %s: %w", path, err)
+	}
+	go c.replayLoop()
+	return &Handler{sink: sink, enc: replay.NewHandler(c), core: c}, nil
+}
+
+// open opens the spool and offset files, picks up the size of the spool
+// and the saved offset, and ends a record torn by a crash, so it does not
+// swallow the next one.
+func (c *core) open() error {
+	f, err := os.OpenFile(c.path, os.O_CREATE|os.O_APPEND|os.O_RDWR, 0o644)
+	if err != nil {
+		return err
+	}
+	off, err := os.OpenFile(c.path+".offset", os.O_CREATE|os.O_RDWR, 0o644)
+	if err != nil {
+		return errors.Join(err, f.Close())
+	}
+	c.f, c.off = f, off
+	if err := c.load(); err != nil {
+		return errors.Join(err, f.Close(), off.Close())
+	}
+	return nil
+}
+
+func (c *core) load() error {
+	st, err := c.f.Stat()
+	if err != nil {
+		return err
+	}
+	c.size = st.Size()
+	if c.size == 0 {
+		return nil
+	}
+	last := make([]byte, 1)
+	if _, err := c.f.ReadAt(last, c.size-1); err != nil {
+		return err
+	}
+	if last[0] != '\n' {
+		if _, err := c.f.Write([]byte{'\n'}); err != nil {
+			return err
+		}
+		c.size++
+	}
+
+	b := make([]byte, offsetLen)
+	if n, _ := c.off.ReadAt(b, 0); n == offsetLen {
+		offset, err := strconv.ParseInt(string(bytes.TrimSpace(b)), 10, 64)
+		if err == nil && offset >= 0 && offset <= c.size {
+			c.offset = offset
+		}
+	}
+	return nil
+}
+
+// offsetLen is the length of the offset saved in the offset file.
+const offsetLen = 21
+
+// saveOffset saves offset to the offset file. c.mu must be held.
+func (c *core) saveOffset(offset int64) error {
+	_, err := c.off.WriteAt(fmt.Appendf(nil, "%020d\n", offset), 0)
+	return err
+}
+
+// Stats returns the spool counters.
+func (h *Handler) Stats() Stats {
+	c := h.core
+	c.mu.Lock()
+	size := c.size - c.offset
+	c.mu.Unlock()
+
+	return Stats{
+		Bytes:     size,
+		Spooled:   c.spooled.Load(),
+		Replayed:  c.replayed.Load(),
+		Dropped:   c.dropped.Load(),
+		Rejected:  c.rejected.Load(),
+		Corrupted: c.corrupted.Load(),
+	}
+}
+
+func (h *Handler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.sink.Enabled(ctx, level)
+}
+
+func (h *Handler) Handle(ctx context.Context, r slog.Record) error {
+	if !h.core.spooling() {
+		if err := h.sink.Handle(ctx, r); err == nil {
+			return nil
+		}
+	}
+	return h.enc.Handle(ctx, r)
+}
+
+func (h *Handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &Handler{sink: h.sink.WithAttrs(attrs), enc: h.enc.WithAttrs(attrs), core: h.core}
+}
+
+func (h *Handler) WithGroup(name string) slog.Handler {
+	return &Handler{sink: h.sink.WithGroup(name), enc: h.enc.WithGroup(name), core: h.core}
+}
+
+// Replay replays the spooled records now, stopping at the first failure.
+func (h *Handler) Replay(ctx context.Context) error {
+	return h.core.replay(ctx)
+}
+
+// Close stops replaying and closes the spool file; spooled records are
+// replayed by the next New.
+func (h *Handler) Close() error {
+	c := h.core
+	select {
+	case <-c.stop:
+		return nil
+	default:
+	}
+	close(c.stop)
+	<-c.done
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return errors.Join(c.f.Close(), c.off.Close())
+}
+
+func (c *core) spooling() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.size > c.offset
+}
+
+// Write appends an encoded envelope, one line written by replay.Handler,
+// as a record.
+func (c *core) Write(p []byte) (int, error) {
+	frame := make([]byte, 0, 9+len(p))
+	frame = fmt.Appendf(frame, "%08x ", crc32.ChecksumIEEE(bytes.TrimSuffix(p, []byte{'\n'})))
+	frame = append(frame, p...)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.opts.maxBytes > 0 && c.size+int64(len(frame)) > c.opts.maxBytes {
+		c.dropped.Add(1)
+		return 0, ErrFull
+	}
+	n, err := c.f.Write(frame)
+	c.size += int64(n)
+	if err != nil {
+		return 0, err
+	}
+	c.spooled.Add(1)
+	return len(p), nil
+}
+
+func (c *core) replayLoop() {
+	defer close(c.done)
+
+	t := time.NewTicker(c.opts.interval)
+	defer t.Stop()
+	for {
+		if c.spooling() {
+			_ = c.replay(context.Background())
+		}
+		select {
+		case <-c.stop:
+			return
+		case <-t.C:
+		}
+	}
+}
+
+// replay passes the spooled records to the sink in order, dropping the
+// replayed ones from the file as it goes, and truncates the file once it is
+// drained.
+func (c *core) replay(ctx context.Context) error {
+	if !c.replaying.CompareAndSwap(false, true) {
+		return nil
+	}
+	defer c.replaying.Store(false)
+
+	for {
+		c.mu.Lock()
+		if c.offset >= c.size {
+			err := c.drained()
+			c.mu.Unlock()
+			return err
+		}
+		if c.offset >= c.opts.compactAt() {
+			if err := c.compact(); err != nil {
+				c.mu.Unlock()
+				return err
+			}
+		}
+		offset := c.offset
+		r := bufio.NewReader(io.NewSectionReader(c.f, offset, c.size-offset))
+		c.mu.Unlock()
+
+		if err := c.replayFrom(ctx, r, offset); err != nil {
+			return err
+		}
+	}
+}
+
+// replayFrom replays the records read from r, which starts at offset,
+// committing the offset after each one. It returns nil at the end of r or
+// once enough replayed records are due for compaction.
+func (c *core) replayFrom(ctx context.Context, r *bufio.Reader, offset int64) error {
+	// suspect is the error of a record the sink failed on maxAttempts
+	// times, skipped if the sink takes the next record.
+	var suspect error
+	var corrupted uint64
+	pos := offset
+	for {
+		line, err := r.ReadBytes('\n')
+		if len(line) == 0 {
+			if err != nil && err != io.EOF {
+				return err
+			}
+			return suspect
+		}
+		start := pos
+		pos += int64(len(line))
+
+		rec, ok := decode(line)
+		if !ok {
+			corrupted++
+		} else if c.sink.Enabled(ctx, rec.Level) {
+			if err := c.sink.Handle(ctx, rec); err != nil {
+				if suspect != nil || !c.failed(start) {
+					return err
+				}
+				suspect = err
+				continue
+			}
+			c.replayed.Add(1)
+			if suspect != nil {
+				c.rejected.Add(1)
+				suspect = nil
+			}
+		}
+		if suspect != nil {
+			continue
+		}
+		c.corrupted.Add(corrupted)
+		corrupted = 0
+
+		c.mu.Lock()
+		c.offset = pos
+		err = c.saveOffset(pos)
+		c.mu.Unlock()
+		if err != nil {
+			return err
+		}
+		if pos >= c.opts.compactAt() {
+			return nil
+		}
+	}
+}
+
+// failed counts a failed replay of the record at offset and reports
+// whether the sink failed on it maxAttempts times in a row.
+func (c *core) failed(offset int64) bool {
+	if c.failOffset != offset {
+		c.failOffset, c.failures = offset, 0
+	}
+	c.failures++
+	return c.failures >= c.opts.maxAttempts
+}
+
+// drained empties the replayed file. c.mu must be held.
+func (c *core) drained() error {
+	if c.size == 0 {
+		return nil
+	}
+	if err := c.f.Truncate(0); err != nil {
+		return err
+	}
+	c.size, c.offset, c.failOffset = 0, 0, -1
+	return c.saveOffset(0)
+}
+
+// compact drops the replayed records from the file by copying the rest to
+// a new one. A crash before the rename replays the old file from the
+// start. c.mu must be held.
+func (c *core) compact() error {
+	tmp := c.path + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_CREATE|os.O_TRUNC|os.O_APPEND|os.O_RDWR, 0o644)
+	if err != nil {
+		return err
+	}
+	n, err := io.Copy(f, io.NewSectionReader(c.f, c.offset, c.size-c.offset))
+	if err == nil {
+		err = c.saveOffset(0)
+	}
+	if err == nil {
+		err = os.Rename(tmp, c.path)
+	}
+	if err != nil {
+		return errors.Join(err, f.Close(), os.Remove(tmp))
+	}
+	_ = c.f.Close()
+	c.f, c.size, c.offset, c.failOffset = f, n, 0, -1
+	return nil
+}
+
+// decode checks and decodes a record line.
+func decode(line []byte) (slog.Record, bool) {
+	line = bytes.TrimSuffix(line, []byte{'\n'})
+	sum, payload, ok := bytes.Cut(line, []byte{' '})
+	if !ok {
+		return slog.Record{}, false
+	}
+	want, err := strconv.ParseUint(string(sum), 16, 32)
+	if err != nil || uint32(want) != crc32.ChecksumIEEE(payload) {
+		return slog.Record{}, false
+	}
+	var e replay.Envelope
+	if err := json.Unmarshal(payload, &e); err != nil {
+		return slog.Record{}, false
+	}
+	rec, err := e.Record()
+	return rec, err == nil
+}
@@ -0,0 +1,87 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"runtime"
+	"runtime/debug"
+)
+
+// LogStartup logs a single "service started" record summarizing the logger
+// configuration, the sinks of the default handler, the resource limits of
+// the process and the build, so every service starts its log the same way.
+// args are added to the record, e.g. feature flags:
+//
+//	logger.LogStartup(slog.Group("features", "new_checkout", true))
+func LogStartup(args ...any) {
+	attrs := make([]slog.Attr, 0, 4)
+	if c := currentConfig.Load(); c != nil {
+		attrs = append(attrs, slog.Group("config",
+			slog.String("level", c.Level),
+			slog.String("format", c.Format),
+			slog.String("time_format", c.TimeFormat),
+			slog.String("time_zone", c.TimeZone),
+			slog.Int("transforms", len(c.Transforms)),
+		))
+	}
+	attrs = append(attrs,
+		slog.Any("sinks", sinkNames(slog.Default().Handler())),
+		slog.Group("runtime",
+			slog.String("go_version", runtime.Version()),
+			slog.Int("gomaxprocs", runtime.GOMAXPROCS(0)),
+			slog.Int("num_cpu", runtime.NumCPU()),
+			slog.Any("memory_limit", memoryLimit()),
+		),
+		buildAttr(),
+	)
+
+	l := slog.Default().With(args...)
+	l.LogAttrs(SourceContext(context.Background(), CallerSource(2)), slog.LevelInfo, "service started", attrs...)
+}
+
+// sinkNames names the destinations of h: the sinks of a MultiHandler, or
+// the handler type otherwise.
+func sinkNames(h slog.Handler) []string {
+	if bh, ok := h.(*BuiltHandler); ok {
+		h = bh.Handler
+	}
+	if ch, ok := h.(ContextHandler); ok {
+		h = ch.Handler
+	}
+	mh, ok := h.(*MultiHandler)
+	if !ok {
+		return []string{fmt.Sprintf("%T", h)}
+	}
+	slots := mh.core.acquire()
+	defer release(slots)
+
+	names := make([]string, len(slots))
+	for i, slot := range slots {
+		names[i] = slot.Name
+	}
+	return names
+}
+
+// memoryLimit returns the GOMEMLIMIT in bytes, or "none" if unset.
+func memoryLimit() any {
+	limit := debug.SetMemoryLimit(-1)
+	if limit == math.MaxInt64 {
+		return "none"
+	}
+	return limit
+}
+
+func buildAttr() slog.Attr {
+	attrs := []any{slog.Bool("lite", liteBuild), slog.Any("features", Features())}
+	if bi, ok := debug.ReadBuildInfo(); ok {
+		attrs = append(attrs, slog.String("path", bi.Main.Path), slog.String("version", bi.Main.Version))
+		for _, s := range bi.Settings {
+			if s.Key == "vcs.revision" {
+				attrs = append(attrs, slog.String("revision", s.Value))
+			}
+		}
+	}
+	return slog.Group("build", attrs...)
+}
@@ -0,0 +1,67 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"log"
+	"log/slog"
+	"sync"
+)
+
+// stdLevels holds the minimum level of every component bridged with
+// NewStdLogger, so they can be tuned centrally at runtime.
+var stdLevels sync.Map
+
+// logger.NewLogger(os.Stdout, logger.WithJSON(true))
+// srv := &http.Server{ErrorLog: logger.NewStdLogger("http", "error")}
+func NewStdLogger(component string, level string, options ...Option) *log.Logger {
+	opts := LoggerOptions(options...)
+	w := &stdWriter{
+		component: component,
+		level:     parseLevel(level),
+		min:       stdLevel(component),
+		skip:      opts.callerSkip,
+		logger:    opts.logger,
+	}
+	return log.New(w, "", 0)
+}
+
+// SetStdLogLevel sets the minimum level of records written by the std
+// loggers of component; records below it are discarded.
+func SetStdLogLevel(component string, level string) {
+	stdLevel(component).Set(parseLevel(level))
+}
+
+func stdLevel(component string) *slog.LevelVar {
+	if lv, ok := stdLevels.Load(component); ok {
+		return lv.(*slog.LevelVar)
+	}
+	lv := new(slog.LevelVar)
+	lv.Set(slog.LevelDebug)
+	actual, _ := stdLevels.LoadOrStore(component, lv)
+	return actual.(*slog.LevelVar)
+}
+
+type stdWriter struct {
+	component string
+	level     slog.Level
+	min       *slog.LevelVar
+	skip      int
+	logger    *slog.Logger
+}
+
+func (w *stdWriter) Write(p []byte) (int, error) {
+	if w.level < w.min.Level() {
+		return len(p), nil
+	}
+
+	// 0 CallerSource, 1 Write, 2 log.(*Logger).output, 3 log.(*Logger).Printf, 4 caller
+	ctx := SourceContext(context.Background(), CallerSource(4+w.skip))
+	msg := string(bytes.TrimRight(p, "\n"))
+	l := w.logger
+	if l == nil {
+		l = slog.Default()
+	}
+	l.Log(ctx, w.level, msg, ComponentKey, w.component)
+	return len(p), nil
+}
@@ -0,0 +1,108 @@
+package logger
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+)
+
+// SwapHandler forwards to a handler that can be replaced at runtime with
+// Swap, e.g. to change format, outputs or wrappers without recreating the
+// loggers already handed out to libraries. Handlers derived with WithAttrs
+// and WithGroup follow the swap, re-applying their attrs and groups.
+type SwapHandler struct {
+	core *swapCore
+	wrap []func(slog.Handler) slog.Handler
+
+	mu      sync.Mutex
+	derived derivedSink
+}
+
+type swapCore struct {
+	mu  sync.RWMutex
+	gen *handlerGen
+}
+
+// handlerGen is one generation of a SwapHandler; Swap waits for the
+// records still being handled by the old one.
+type handlerGen struct {
+	handler slog.Handler
+	id      uint64
+	active  sync.WaitGroup
+}
+
+// h := logger.NewSwapHandler(slog.NewTextHandler(os.Stdout, nil))
+// slog.SetDefault(slog.New(h))
+// h.Swap(slog.NewJSONHandler(os.Stdout, nil))
+func NewSwapHandler(h slog.Handler) *SwapHandler {
+	return &SwapHandler{core: &swapCore{gen: &handlerGen{handler: h}}}
+}
+
+// Swap replaces the handler of h and all handlers derived from it with
+// next. It returns the old handler once it finished the records it was
+// handling, so it can be closed.
+func (h *SwapHandler) Swap(next slog.Handler) slog.Handler {
+	c := h.core
+	c.mu.Lock()
+	old := c.gen
+	c.gen = &handlerGen{handler: next, id: old.id + 1}
+	c.mu.Unlock()
+
+	old.active.Wait()
+	return old.handler
+}
+
+// acquire returns the current generation, marked active until Done.
+func (c *swapCore) acquire() *handlerGen {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	gen := c.gen
+	gen.active.Add(1)
+	return gen
+}
+
+// handler returns the handler of gen with h's attrs and groups applied,
+// rebuilding it after a swap.
+func (h *SwapHandler) handler(gen *handlerGen) slog.Handler {
+	if len(h.wrap) == 0 {
+		return gen.handler
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.derived.handler != nil && h.derived.gen == gen.id {
+		return h.derived.handler
+	}
+	sh := gen.handler
+	for _, w := range h.wrap {
+		sh = w(sh)
+	}
+	h.derived = derivedSink{gen: gen.id, handler: sh}
+	return sh
+}
+
+func (h *SwapHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	gen := h.core.acquire()
+	defer gen.active.Done()
+	return h.handler(gen).Enabled(ctx, level)
+}
+
+func (h *SwapHandler) Handle(ctx context.Context, r slog.Record) error {
+	gen := h.core.acquire()
+	defer gen.active.Done()
+	return h.handler(gen).Handle(ctx, r)
+}
+
+func (h *SwapHandler) derive(w func(slog.Handler) slog.Handler) *SwapHandler {
+	return &SwapHandler{core: h.core, wrap: append(h.wrap[:len(h.wrap):len(h.wrap)], w)}
+}
+
+func (h *SwapHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return h.derive(func(sh slog.Handler) slog.Handler { return sh.WithAttrs(attrs) })
+}
+
+func (h *SwapHandler) WithGroup(name string) slog.Handler {
+	return h.derive(func(sh slog.Handler) slog.Handler { return sh.WithGroup(name) })
+}
@@ -0,0 +1,247 @@
+package logger
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// TenantPolicy configures limits and redaction for a single tenant.
+// Zero values disable the corresponding limit.
+type TenantPolicy struct {
+	RateLimit   float64       `json:"rate_limit,omitempty"`
+	Burst       int           `json:"burst,omitempty"`
+	QuotaWindow time.Duration `json:"quota_window,omitempty"`
+	QuotaCount  int64         `json:"quota_count,omitempty"`
+	QuotaBytes  int64         `json:"quota_bytes,omitempty"`
+	Redact      RedactProfile `json:"redact,omitempty"`
+}
+
+// TenantHandler partitions records by the value of a tenant attr. Each tenant
+// gets its own handler from newHandler (a file, a labelled network sink, ...)
+// plus the limits of its TenantPolicy. Policies are looked up by tenant name,
+// falling back to the "*" entry; records without the attr go to tenant "".
+//
+// Up to maxTenants tenants are kept; beyond that the least recently used
+// one is dropped, closing its handler if it is an io.Closer, and gets a new
+// handler and fresh limits if it logs again. A record still in flight to a
+// dropped handler may then fail.
+type TenantHandler struct {
+	core    *tenantCore
+	tenant  string
+	grouped bool
+	wrap    []func(slog.Handler) slog.Handler
+
+	mu       sync.Mutex
+	handlers map[string]tenantHandler
+}
+
+// tenantHandler is the handler of a tenant with the WithAttrs and WithGroup
+// calls of a derived TenantHandler applied, and the state it was built from.
+type tenantHandler struct {
+	st      *tenantState
+	handler slog.Handler
+}
+
+// maxTenants bounds the tenants a TenantHandler keeps handlers and limits
+// for.
+const maxTenants = 1024
+
+type tenantCore struct {
+	key        string
+	newHandler func(tenant string) slog.Handler
+	policies   map[string]TenantPolicy
+
+	mu      sync.Mutex
+	tenants map[string]*tenantState
+}
+
+type tenantState struct {
+	handler slog.Handler
+	closer  io.Closer
+	limiter *rateLimiter
+	quota   *windowQuota
+	used    atomic.Int64
+}
+
+//	logger.NewTenantHandler("tenant_id", func(tenant string) slog.Handler {
+//		f, _ := os.OpenFile(tenant+".log", os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+//		return logger.NewBufferedHandler(f, func(w io.Writer) slog.Handler { return slog.NewJSONHandler(w, nil) })
+//	}, map[string]logger.TenantPolicy{"*": {RateLimit: 100, Burst: 100}})
+func NewTenantHandler(key string, newHandler func(tenant string) slog.Handler, policies map[string]TenantPolicy) *TenantHandler {
+	return &TenantHandler{
+		core: &tenantCore{
+			key:        key,
+			newHandler: newHandler,
+			policies:   policies,
+			tenants:    make(map[string]*tenantState),
+		},
+		handlers: make(map[string]tenantHandler),
+	}
+}
+
+// state returns the state of tenant, building it outside the lock if it is
+// new.
+func (c *tenantCore) state(tenant string) *tenantState {
+	c.mu.Lock()
+	st, ok := c.tenants[tenant]
+	c.mu.Unlock()
+	if ok {
+		st.used.Store(time.Now().UnixNano())
+		return st
+	}
+
+	st = c.build(tenant)
+
+	c.mu.Lock()
+	if cur, ok := c.tenants[tenant]; ok {
+		c.mu.Unlock()
+		st.close()
+		return cur
+	}
+	var evicted *tenantState
+	if len(c.tenants) >= maxTenants {
+		evicted = c.evict()
+	}
+	c.tenants[tenant] = st
+	c.mu.Unlock()
+
+	if evicted != nil {
+		evicted.close()
+	}
+	return st
+}
+
+func (c *tenantCore) build(tenant string) *tenantState {
+	p, ok := c.policies[tenant]
+	if !ok {
+		p = c.policies["*"]
+	}
+
+	st := &tenantState{handler: c.newHandler(tenant)}
+	st.closer, _ = st.handler.(io.Closer)
+	if len(p.Redact.Keys) > 0 {
+		st.handler = NewRedactHandler(st.handler, p.Redact)
+	}
+	if p.RateLimit > 0 {
+		st.limiter = newRateLimiter(p.RateLimit, p.Burst)
+	}
+	if p.QuotaCount > 0 || p.QuotaBytes > 0 {
+		st.quota = newWindowQuota(p.QuotaWindow, p.QuotaCount, p.QuotaBytes)
+	}
+	st.used.Store(time.Now().UnixNano())
+	return st
+}
+
+// evict drops the least recently used tenant and returns its state. c.mu
+// must be held.
+func (c *tenantCore) evict() *tenantState {
+	var oldest string
+	var used int64
+	for tenant, st := range c.tenants {
+		if u := st.used.Load(); used == 0 || u < used {
+			oldest, used = tenant, u
+		}
+	}
+	st := c.tenants[oldest]
+	delete(c.tenants, oldest)
+	return st
+}
+
+// close closes the handler built for st if it is an io.Closer.
+func (st *tenantState) close() {
+	if st.closer != nil {
+		_ = st.closer.Close()
+	}
+}
+
+func (st *tenantState) allow(r slog.Record) bool {
+	if st.limiter != nil && !st.limiter.allow(r.Time) {
+		return false
+	}
+	if st.quota != nil {
+		if ok, _ := st.quota.allow(r.Time, recordSize(r)); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// handler returns the handler of st, the state of tenant, with the derived
+// calls applied, building it outside the lock.
+func (h *TenantHandler) handler(tenant string, st *tenantState) slog.Handler {
+	h.mu.Lock()
+	th, ok := h.handlers[tenant]
+	h.mu.Unlock()
+	if ok && th.st == st {
+		return th.handler
+	}
+
+	th = tenantHandler{st: st, handler: st.handler}
+	for _, w := range h.wrap {
+		th.handler = w(th.handler)
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if len(h.handlers) >= maxTenants {
+		clear(h.handlers)
+	}
+	h.handlers[tenant] = th
+	return th.handler
+}
+
+// Enabled reports true: the tenant of a record, and so the handler whose
+// level applies, is only known in Handle.
+func (h *TenantHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return true
+}
+
+func (h *TenantHandler) Handle(ctx context.Context, r slog.Record) error {
+	tenant := h.tenant
+	r.Attrs(func(a slog.Attr) bool {
+		if a.Key == h.core.key {
+			tenant = a.Value.String()
+			return false
+		}
+		return true
+	})
+
+	st := h.core.state(tenant)
+	th := h.handler(tenant, st)
+	if !th.Enabled(ctx, r.Level) || (!forced(ctx, r) && !st.allow(r)) {
+		return nil
+	}
+	return th.Handle(ctx, r)
+}
+
+func (h *TenantHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	tenant := h.tenant
+	if !h.grouped {
+		for _, a := range attrs {
+			if a.Key == h.core.key {
+				tenant = a.Value.String()
+			}
+		}
+	}
+	return h.derive(tenant, h.grouped, func(th slog.Handler) slog.Handler { return th.WithAttrs(attrs) })
+}
+
+func (h *TenantHandler) WithGroup(name string) slog.Handler {
+	return h.derive(h.tenant, true, func(th slog.Handler) slog.Handler { return th.WithGroup(name) })
+}
+
+func (h *TenantHandler) derive(tenant string, grouped bool, w func(slog.Handler) slog.Handler) *TenantHandler {
+	wrap := make([]func(slog.Handler) slog.Handler, len(h.wrap), len(h.wrap)+1)
+	copy(wrap, h.wrap)
+	return &TenantHandler{
+		core:     h.core,
+		tenant:   tenant,
+		grouped:  grouped,
+		wrap:     append(wrap, w),
+		handlers: make(map[string]tenantHandler),
+	}
+}
@@ -0,0 +1,64 @@
+package logger
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+type TimerOption func(*timerOptions)
+
+type timerOptions struct {
+	threshold time.Duration
+	level     slog.Level
+}
+
+// WithTimerThreshold only logs operations slower than threshold.
+func WithTimerThreshold(threshold time.Duration) TimerOption {
+	return func(opts *timerOptions) {
+		opts.threshold = threshold
+	}
+}
+
+// WithTimerLevel sets the level of the record. Default: INFO.
+func WithTimerLevel(level slog.Level) TimerOption {
+	return func(opts *timerOptions) {
+		opts.level = level
+	}
+}
+
+func TimerOptions(options ...TimerOption) *timerOptions {
+	opts := &timerOptions{level: slog.LevelInfo}
+	for _, opt := range options {
+		opt(opts)
+	}
+	return opts
+}
+
+// Timer starts timing an operation; the returned stop function logs name
+// with the elapsed milliseconds and any args, and returns the elapsed time.
+// Readings come from the monotonic clock, so wall clock changes do not
+// skew them.
+//
+//	stop := logger.Timer(ctx, "render", logger.WithTimerThreshold(100*time.Millisecond))
+//	defer stop("template", name)
+func Timer(ctx context.Context, name string, options ...TimerOption) func(args ...any) time.Duration {
+	opts := TimerOptions(options...)
+	begin := time.Now()
+
+	return func(args ...any) time.Duration {
+		elapsed := time.Since(begin)
+		if elapsed < opts.threshold {
+			return elapsed
+		}
+
+		l := slog.Default()
+		sctx := SourceContext(ctx, CallerSource(2))
+		if !l.Enabled(sctx, opts.level) {
+			return elapsed
+		}
+		l.With(args...).LogAttrs(sctx, opts.level, name,
+			slog.Float64("ms", float64(elapsed.Nanoseconds())/1e6))
+		return elapsed
+	}
+}
@@ -0,0 +1,273 @@
+package logger
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"sort"
+	"time"
+)
+
+const (
+	TransformRename    string = "rename"
+	TransformDrop      string = "drop"
+	TransformCopy      string = "copy"
+	TransformParseJSON string = "parse_json"
+	TransformConvert   string = "convert"
+	TransformNormalize string = "normalize"
+)
+
+// Transform is a single declarative attr rule, e.g.
+// {"op":"rename","key":"msisdn","to":"phone"}
+// {"op":"convert","key":"elapsed","from":"ns","unit":"ms"}
+// {"op":"normalize","key":"cached","unit":"bool"}
+type Transform struct {
+	Op   string `json:"op"`
+	Key  string `json:"key"`
+	To   string `json:"to,omitempty"`
+	From string `json:"from,omitempty"`
+	Unit string `json:"unit,omitempty"`
+}
+
+var transformUnits = map[string]map[string]float64{
+	"duration": {
+		"ns": float64(time.Nanosecond),
+		"us": float64(time.Microsecond),
+		"ms": float64(time.Millisecond),
+		"s":  float64(time.Second),
+		"m":  float64(time.Minute),
+		"h":  float64(time.Hour),
+	},
+	"size": {
+		"b":  1,
+		"kb": 1 << 10,
+		"mb": 1 << 20,
+		"gb": 1 << 30,
+	},
+}
+
+func unitFactors(from, to string) (float64, float64, bool) {
+	for _, units := range transformUnits {
+		f, fok := units[from]
+		t, tok := units[to]
+		if fok && tok {
+			return f, t, true
+		}
+	}
+	return 0, 0, false
+}
+
+func (t Transform) validate() error {
+	if t.Key == "" {
+		return fmt.Errorf("transform %q: empty key", t.Op)
+	}
+	switch t.Op {
+	case TransformDrop, TransformParseJSON:
+	case TransformRename, TransformCopy:
+		if t.To == "" {
+			return fmt.Errorf("transform %q %q: empty target key", t.Op, t.Key)
+		}
+	case TransformConvert:
+		if _, _, ok := unitFactors(t.From, t.Unit); !ok {
+			return fmt.Errorf("transform %q %q: cannot convert %q to %q", t.Op, t.Key, t.From, t.Unit)
+		}
+	case TransformNormalize:
+		return t.validateNormalize()
+	default:
+		return fmt.Errorf("transform %q: unknown op", t.Op)
+	}
+	return nil
+}
+
+// rules, err := logger.LoadTransforms(f)
+// logger.NewLogger(os.Stdout, logger.WithTransforms(rules...))
+func LoadTransforms(r io.Reader) ([]Transform, error) {
+	var rules []Transform
+	if err := json.NewDecoder(r).Decode(&rules); err != nil {
+		return nil, fmt.Errorf("decode transforms: %w", err)
+	}
+	for _, t := range rules {
+		if err := t.validate(); err != nil {
+			return nil, err
+		}
+	}
+	return rules, nil
+}
+
+func LoadTransformsFile(name string) ([]Transform, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return LoadTransforms(f)
+}
+
+// TransformHandler applies Transform rules to every attr, including attrs
+// added with WithAttrs and members of groups.
+type TransformHandler struct {
+	slog.Handler
+	rules []Transform
+	keys  map[string]struct{}
+}
+
+// NewTransformHandler fails if a rule is invalid.
+func NewTransformHandler(h slog.Handler, rules ...Transform) (*TransformHandler, error) {
+	th := &TransformHandler{Handler: h, keys: make(map[string]struct{})}
+	var errs []error
+	for _, t := range rules {
+		if err := t.validate(); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		th.rules = append(th.rules, t)
+		th.keys[t.Key] = struct{}{}
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return th, nil
+}
+
+func (h *TransformHandler) Handle(ctx context.Context, r slog.Record) error {
+	matched := false
+	r.Attrs(func(a slog.Attr) bool {
+		matched = h.match(a)
+		return !matched
+	})
+	if !matched {
+		return h.Handler.Handle(ctx, r)
+	}
+
+	attrs := make([]slog.Attr, 0, r.NumAttrs())
+	r.Attrs(func(a slog.Attr) bool {
+		attrs = append(attrs, h.apply(a)...)
+		return true
+	})
+	nr := slog.NewRecord(r.Time, r.Level, r.Message, r.PC)
+	nr.AddAttrs(attrs...)
+	return h.Handler.Handle(ctx, nr)
+}
+
+func (h *TransformHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	var out []slog.Attr
+	for _, a := range attrs {
+		out = append(out, h.apply(a)...)
+	}
+	return &TransformHandler{Handler: h.Handler.WithAttrs(out), rules: h.rules, keys: h.keys}
+}
+
+func (h *TransformHandler) WithGroup(name string) slog.Handler {
+	return &TransformHandler{Handler: h.Handler.WithGroup(name), rules: h.rules, keys: h.keys}
+}
+
+func (h *TransformHandler) match(a slog.Attr) bool {
+	if _, ok := h.keys[a.Key]; ok {
+		return true
+	}
+	if a.Value.Kind() == slog.KindGroup {
+		for _, ga := range a.Value.Group() {
+			if h.match(ga) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (h *TransformHandler) apply(a slog.Attr) []slog.Attr {
+	if !h.match(a) {
+		return []slog.Attr{a}
+	}
+
+	if a.Value.Kind() == slog.KindGroup {
+		var members []slog.Attr
+		for _, ga := range a.Value.Group() {
+			members = append(members, h.apply(ga)...)
+		}
+		a = slog.Attr{Key: a.Key, Value: slog.GroupValue(members...)}
+	}
+
+	attrs := []slog.Attr{a}
+	for _, t := range h.rules {
+		var next []slog.Attr
+		for _, a := range attrs {
+			if a.Key != t.Key {
+				next = append(next, a)
+				continue
+			}
+			next = append(next, t.apply(a)...)
+		}
+		attrs = next
+	}
+	return attrs
+}
+
+func (t Transform) apply(a slog.Attr) []slog.Attr {
+	switch t.Op {
+	case TransformRename:
+		return []slog.Attr{{Key: t.To, Value: a.Value}}
+	case TransformDrop:
+		return nil
+	case TransformCopy:
+		return []slog.Attr{a, {Key: t.To, Value: a.Value}}
+	case TransformParseJSON:
+		key := a.Key
+		if t.To != "" {
+			key = t.To
+		}
+		var v any
+		if a.Value.Kind() != slog.KindString || json.Unmarshal([]byte(a.Value.String()), &v) != nil {
+			return []slog.Attr{a}
+		}
+		return []slog.Attr{jsonAttr(key, v)}
+	case TransformConvert:
+		from, to, _ := unitFactors(t.From, t.Unit)
+		var n float64
+		switch a.Value.Kind() {
+		case slog.KindDuration:
+			// a duration is taken in the From unit, so it only converts
+			// between duration units
+			if _, ok := transformUnits["duration"][t.From]; !ok {
+				return []slog.Attr{a}
+			}
+			n = float64(a.Value.Duration()) / from
+		case slog.KindInt64:
+			n = float64(a.Value.Int64())
+		case slog.KindUint64:
+			n = float64(a.Value.Uint64())
+		case slog.KindFloat64:
+			n = a.Value.Float64()
+		default:
+			return []slog.Attr{a}
+		}
+		return []slog.Attr{slog.Float64(a.Key, n*from/to)}
+	case TransformNormalize:
+		return []slog.Attr{t.normalize(a)}
+	}
+	return []slog.Attr{a}
+}
+
+func jsonAttr(key string, v any) slog.Attr {
+	m, ok := v.(map[string]any)
+	if !ok {
+		return slog.Any(key, v)
+	}
+
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	attrs := make([]slog.Attr, 0, len(keys))
+	for _, k := range keys {
+		attrs = append(attrs, jsonAttr(k, m[k]))
+	}
+	return slog.Attr{Key: key, Value: slog.GroupValue(attrs...)}
+}
@@ -0,0 +1,159 @@
+package transport
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+// TLSConfig configures server verification and client certificates. The
+// client certificate is reloaded on handshake when CertFile changes on disk,
+// so rotated certificates are picked up without a restart.
+type TLSConfig struct {
+	CAFile             string `json:"ca_file,omitempty"`
+	CertFile           string `json:"cert_file,omitempty"`
+	KeyFile            string `json:"key_file,omitempty"`
+	ServerName         string `json:"server_name,omitempty"`
+	InsecureSkipVerify bool   `json:"insecure_skip_verify,omitempty"`
+}
+
+func (c TLSConfig) load() (*tls.Config, error) {
+	cfg := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		ServerName:         c.ServerName,
+		InsecureSkipVerify: c.InsecureSkipVerify, //nolint:gosec
+	}
+
+	if c.CAFile != "" {
+		pem, err := os.ReadFile(c.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("transport: read ca: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("transport: no certificates in %s", c.CAFile)
+		}
+		cfg.RootCAs = pool
+	}
+
+	if c.CertFile != "" || c.KeyFile != "" {
+		r := &certReloader{certFile: c.CertFile, keyFile: c.KeyFile}
+		if _, err := r.certificate(); err != nil {
+			return nil, err
+		}
+		cfg.GetClientCertificate = func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			return r.certificate()
+		}
+	}
+	return cfg, nil
+}
+
+type certReloader struct {
+	certFile string
+	keyFile  string
+
+	mu      sync.Mutex
+	cert    *tls.Certificate
+	modTime time.Time
+}
+
+func (r *certReloader) certificate() (*tls.Certificate, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	info, err := os.Stat(r.certFile)
+	if err != nil {
+		if r.cert != nil {
+			return r.cert, nil
+		}
+		return nil, fmt.Errorf("transport: stat cert: %w", err)
+	}
+	if r.cert != nil && info.ModTime().Equal(r.modTime) {
+		return r.cert, nil
+	}
+
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		if r.cert != nil {
+			return r.cert, nil
+		}
+		return nil, fmt.Errorf("transport: load client cert: %w", err)
+	}
+	r.cert, r.modTime = &cert, info.ModTime()
+	return r.cert, nil
+}
+
+func WithTLS(cfg TLSConfig) Option {
+	return func(opts *clientOptions) {
+		opts.tls = &cfg
+	}
+}
+
+// TokenFunc returns the current credential. It is called for every request,
+// so rotating credentials only requires returning the new value.
+type TokenFunc func(ctx context.Context) (string, error)
+
+func StaticToken(token string) TokenFunc {
+	return func(context.Context) (string, error) {
+		return token, nil
+	}
+}
+
+// CachedToken calls fetch only when the previous token has expired, e.g. for
+// OAuth client credentials. refresh is called with every newly fetched token.
+func CachedToken(fetch func(ctx context.Context) (string, time.Time, error), refresh func(token string)) TokenFunc {
+	var (
+		mu      sync.Mutex
+		token   string
+		expires time.Time
+	)
+	return func(ctx context.Context) (string, error) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		if token != "" && time.Now().Before(expires) {
+			return token, nil
+		}
+		t, exp, err := fetch(ctx)
+		if err != nil {
+			return "", err
+		}
+		token, expires = t, exp
+		if refresh != nil {
+			refresh(token)
+		}
+		return token, nil
+	}
+}
+
+// WithBearer sets "Authorization: Bearer <token>" on every request.
+func WithBearer(token TokenFunc) Option {
+	return WithAuth("Authorization", "Bearer ", token)
+}
+
+// WithAPIKey sets header to the key on every request, e.g. "X-API-Key" or
+// "Authorization" with a "Splunk " prefix via WithAuth.
+func WithAPIKey(header string, key TokenFunc) Option {
+	return WithAuth(header, "", key)
+}
+
+func WithAuth(header, prefix string, token TokenFunc) Option {
+	return func(opts *clientOptions) {
+		opts.auth = append(opts.auth, func(req *http.Request) error {
+			t, err := token(req.Context())
+			if err != nil {
+				return errors.Join(errAuth, err)
+			}
+			req.Header.Set(header, prefix+t)
+			return nil
+		})
+	}
+}
+
+var errAuth = errors.New("transport: credentials")
@@ -0,0 +1,229 @@
+package transport
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+const dlqExt = ".dlq"
+
+// DeadLetterStats reports the state of the dead-letter queue.
+type DeadLetterStats struct {
+	Depth    int    `json:"depth"`
+	Bytes    int64  `json:"bytes"`
+	Queued   uint64 `json:"queued"`
+	Replayed uint64 `json:"replayed"`
+	Dropped  uint64 `json:"dropped"`
+}
+
+// WithDeadLetter persists payloads that still fail after all retries to dir,
+// keeping at most maxBytes on disk by dropping the oldest entries, and
+// replays them once a later request succeeds, from a single background
+// goroutine started by the first successful request.
+func WithDeadLetter(dir string, maxBytes int64) Option {
+	return func(opts *clientOptions) {
+		opts.deadLetter = &deadLetter{dir: dir, maxBytes: maxBytes, wake: make(chan struct{}, 1)}
+	}
+}
+
+type deadLetter struct {
+	dir      string
+	maxBytes int64
+
+	once    sync.Once
+	initErr error
+
+	mu    sync.Mutex
+	seq   uint64
+	files []string
+	sizes map[string]int64
+	bytes int64
+
+	loop      sync.Once
+	wake      chan struct{}
+	replaying atomic.Bool
+	queued    atomic.Uint64
+	replayed  atomic.Uint64
+	dropped   atomic.Uint64
+}
+
+func (d *deadLetter) init() error {
+	d.once.Do(func() {
+		d.sizes = make(map[string]int64)
+		if d.initErr = os.MkdirAll(d.dir, 0o755); d.initErr != nil {
+			return
+		}
+		entries, err := os.ReadDir(d.dir)
+		if err != nil {
+			d.initErr = err
+			return
+		}
+		for _, e := range entries {
+			name := e.Name()
+			if e.IsDir() || !strings.HasSuffix(name, dlqExt) {
+				continue
+			}
+			info, err := e.Info()
+			if err != nil {
+				continue
+			}
+			seq, _ := strconv.ParseUint(strings.TrimSuffix(name, dlqExt), 10, 64)
+			if seq > d.seq {
+				d.seq = seq
+			}
+			d.files = append(d.files, name)
+			d.sizes[name] = info.Size()
+			d.bytes += info.Size()
+		}
+		sort.Strings(d.files)
+	})
+	return d.initErr
+}
+
+func (d *deadLetter) push(url string, payload []byte) error {
+	if err := d.init(); err != nil {
+		return err
+	}
+
+	size := int64(len(url) + 1 + len(payload))
+	if d.maxBytes > 0 && size > d.maxBytes {
+		d.dropped.Add(1)
+		return nil
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	for d.maxBytes > 0 && d.bytes+size > d.maxBytes && len(d.files) > 0 {
+		d.remove(d.files[0])
+		d.dropped.Add(1)
+	}
+
+	d.seq++
+	name := fmt.Sprintf("%020d%s", d.seq, dlqExt)
+	tmp := filepath.Join(d.dir, name+".tmp")
+	data := make([]byte, 0, size)
+	data = append(append(append(data, url...), '\n'), payload...)
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, filepath.Join(d.dir, name)); err != nil {
+		return err
+	}
+
+	d.files = append(d.files, name)
+	d.sizes[name] = size
+	d.bytes += size
+	d.queued.Add(1)
+	return nil
+}
+
+// remove deletes a queued file; d.mu must be held.
+func (d *deadLetter) remove(name string) {
+	_ = os.Remove(filepath.Join(d.dir, name))
+	d.bytes -= d.sizes[name]
+	delete(d.sizes, name)
+	for i, f := range d.files {
+		if f == name {
+			d.files = append(d.files[:i], d.files[i+1:]...)
+			break
+		}
+	}
+}
+
+func (d *deadLetter) replay(ctx context.Context, send func(ctx context.Context, url string, payload []byte) error) error {
+	if err := d.init(); err != nil {
+		return err
+	}
+	if !d.replaying.CompareAndSwap(false, true) {
+		return nil
+	}
+	defer d.replaying.Store(false)
+
+	for {
+		d.mu.Lock()
+		if len(d.files) == 0 {
+			d.mu.Unlock()
+			return nil
+		}
+		name := d.files[0]
+		d.mu.Unlock()
+
+		data, err := os.ReadFile(filepath.Join(d.dir, name))
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+		if url, payload, ok := bytes.Cut(data, []byte{'\n'}); ok {
+			if err := send(ctx, string(url), payload); err != nil {
+				return err
+			}
+			d.replayed.Add(1)
+		} else {
+			// deleted or truncated behind our back
+			d.dropped.Add(1)
+		}
+
+		d.mu.Lock()
+		d.remove(name)
+		d.mu.Unlock()
+	}
+}
+
+// notify wakes the replay loop, starting it on the first call.
+func (d *deadLetter) notify(send func(ctx context.Context, url string, payload []byte) error) {
+	d.loop.Do(func() {
+		go func() {
+			for range d.wake {
+				_ = d.replay(context.Background(), send)
+			}
+		}()
+	})
+	select {
+	case d.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (d *deadLetter) stats() DeadLetterStats {
+	if d.init() != nil {
+		return DeadLetterStats{}
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return DeadLetterStats{
+		Depth:    len(d.files),
+		Bytes:    d.bytes,
+		Queued:   d.queued.Load(),
+		Replayed: d.replayed.Load(),
+		Dropped:  d.dropped.Load(),
+	}
+}
+
+// DeadLetterStats returns the dead-letter queue counters, or zero values when
+// WithDeadLetter is not configured.
+func (c *Client) DeadLetterStats() DeadLetterStats {
+	if c.opts.deadLetter == nil {
+		return DeadLetterStats{}
+	}
+	return c.opts.deadLetter.stats()
+}
+
+// Replay sends queued dead letters in order, stopping at the first failure.
+func (c *Client) Replay(ctx context.Context) error {
+	if c.opts.deadLetter == nil {
+		return nil
+	}
+	return c.opts.deadLetter.replay(ctx, c.post)
+}
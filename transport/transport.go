@@ -0,0 +1,287 @@
+package transport
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+const (
+	CompressionNone string = ""
+	CompressionGzip string = "gzip"
+	CompressionZstd string = "zstd"
+)
+
+type Option func(*clientOptions)
+
+type clientOptions struct {
+	compression  string
+	headers      http.Header
+	timeout      time.Duration
+	maxAttempts  int
+	backoff      time.Duration
+	maxBackoff   time.Duration
+	maxIdleConns int
+	proxy        func(*http.Request) (*url.URL, error)
+	contentType  string
+	deadLetter   *deadLetter
+	tls          *TLSConfig
+	auth         []func(*http.Request) error
+}
+
+func WithCompression(compression string) Option {
+	return func(opts *clientOptions) {
+		opts.compression = compression
+	}
+}
+
+func WithHeader(key, value string) Option {
+	return func(opts *clientOptions) {
+		opts.headers.Add(key, value)
+	}
+}
+
+func WithTimeout(timeout time.Duration) Option {
+	return func(opts *clientOptions) {
+		opts.timeout = timeout
+	}
+}
+
+// WithRetry sets the number of attempts per request and the base delay of
+// the jittered exponential backoff between them, and its cap. A negative
+// backoff is taken as zero, retrying at once, and maxBackoff is at least
+// backoff.
+func WithRetry(maxAttempts int, backoff, maxBackoff time.Duration) Option {
+	return func(opts *clientOptions) {
+		opts.maxAttempts = max(maxAttempts, 1)
+		opts.backoff = min(max(backoff, 0), math.MaxInt64-1)
+		opts.maxBackoff = min(max(maxBackoff, opts.backoff), math.MaxInt64-1)
+	}
+}
+
+func WithMaxIdleConns(n int) Option {
+	return func(opts *clientOptions) {
+		opts.maxIdleConns = n
+	}
+}
+
+// WithProxy overrides the proxy taken from HTTP_PROXY/HTTPS_PROXY/NO_PROXY.
+func WithProxy(proxyURL string) Option {
+	return func(opts *clientOptions) {
+		u, err := url.Parse(proxyURL)
+		if err != nil {
+			opts.proxy = func(*http.Request) (*url.URL, error) { return nil, err }
+			return
+		}
+		opts.proxy = http.ProxyURL(u)
+	}
+}
+
+func WithContentType(contentType string) Option {
+	return func(opts *clientOptions) {
+		opts.contentType = contentType
+	}
+}
+
+// Client is the HTTP plumbing shared by network sinks.
+type Client struct {
+	opts *clientOptions
+	http *http.Client
+	err  error
+}
+
+// c := transport.New(transport.WithCompression(transport.CompressionGzip))
+// h := slog.NewJSONHandler(c.Writer("https://collector/logs"), nil)
+func New(options ...Option) *Client {
+	opts := &clientOptions{
+		headers:      make(http.Header),
+		timeout:      10 * time.Second,
+		maxAttempts:  3,
+		backoff:      100 * time.Millisecond,
+		maxBackoff:   5 * time.Second,
+		maxIdleConns: 16,
+		proxy:        http.ProxyFromEnvironment,
+		contentType:  "application/x-ndjson",
+	}
+	for _, opt := range options {
+		opt(opts)
+	}
+
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	t.Proxy = opts.proxy
+	t.MaxIdleConns = opts.maxIdleConns
+	t.MaxIdleConnsPerHost = opts.maxIdleConns
+
+	var err error
+	if opts.tls != nil {
+		t.TLSClientConfig, err = opts.tls.load()
+	}
+
+	return &Client{
+		opts: opts,
+		http: &http.Client{Transport: t, Timeout: opts.timeout},
+		err:  err,
+	}
+}
+
+// StatusError is returned for non-2xx responses.
+type StatusError struct {
+	StatusCode int
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("transport: unexpected status %d", e.StatusCode)
+}
+
+func (e *StatusError) retryable() bool {
+	return e.StatusCode == http.StatusTooManyRequests || e.StatusCode >= 500
+}
+
+// Post sends body to url, compressing it and retrying with jittered
+// exponential backoff on network errors, 429 and 5xx responses. With
+// WithDeadLetter, payloads that still fail are queued on disk instead.
+func (c *Client) Post(ctx context.Context, url string, body []byte) error {
+	if c.err != nil {
+		return c.err
+	}
+
+	payload, err := c.compress(body)
+	if err != nil {
+		return err
+	}
+
+	if err := c.send(ctx, url, payload); err != nil {
+		if dl := c.opts.deadLetter; dl != nil {
+			if qerr := dl.push(url, payload); qerr != nil {
+				return errors.Join(err, qerr)
+			}
+			return nil
+		}
+		return err
+	}
+
+	if dl := c.opts.deadLetter; dl != nil {
+		dl.notify(c.post)
+	}
+	return nil
+}
+
+func (c *Client) send(ctx context.Context, url string, payload []byte) error {
+	backoff := c.opts.backoff
+	for attempt := 1; ; attempt++ {
+		err := c.post(ctx, url, payload)
+		if err == nil {
+			return nil
+		}
+
+		var se *StatusError
+		if (errors.As(err, &se) && !se.retryable()) || errors.Is(err, errAuth) {
+			return err
+		}
+		if attempt >= c.opts.maxAttempts {
+			return err
+		}
+
+		delay := time.Duration(rand.Int63n(int64(backoff) + 1))
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+		if backoff > c.opts.maxBackoff/2 {
+			backoff = c.opts.maxBackoff
+		} else {
+			backoff *= 2
+		}
+	}
+}
+
+func (c *Client) post(ctx context.Context, url string, payload []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	for k, v := range c.opts.headers {
+		req.Header[k] = v
+	}
+	for _, auth := range c.opts.auth {
+		if err := auth(req); err != nil {
+			return err
+		}
+	}
+	req.Header.Set("Content-Type", c.opts.contentType)
+	if c.opts.compression != CompressionNone {
+		req.Header.Set("Content-Encoding", c.opts.compression)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return &StatusError{StatusCode: resp.StatusCode}
+	}
+	return nil
+}
+
+func (c *Client) compress(body []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	switch c.opts.compression {
+	case CompressionNone:
+		return body, nil
+	case CompressionGzip:
+		zw := gzip.NewWriter(&buf)
+		if _, err := zw.Write(body); err != nil {
+			return nil, err
+		}
+		if err := zw.Close(); err != nil {
+			return nil, err
+		}
+	case CompressionZstd:
+		zw, err := zstd.NewWriter(&buf)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := zw.Write(body); err != nil {
+			return nil, err
+		}
+		if err := zw.Close(); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("transport: unknown compression %q", c.opts.compression)
+	}
+	return buf.Bytes(), nil
+}
+
+// Writer returns an io.Writer that posts every Write to url. slog handlers
+// write one record per call, so each record becomes one request.
+func (c *Client) Writer(url string) io.Writer {
+	return writerFunc(func(p []byte) (int, error) {
+		body := make([]byte, len(p))
+		copy(body, p)
+		if err := c.Post(context.Background(), url, body); err != nil {
+			return 0, err
+		}
+		return len(p), nil
+	})
+}
+
+type writerFunc func(p []byte) (int, error)
+
+func (f writerFunc) Write(p []byte) (int, error) {
+	return f(p)
+}